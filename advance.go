@@ -0,0 +1,232 @@
+package bdf
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/image/math/fixed"
+)
+
+type advanceCache struct {
+	mu       sync.RWMutex
+	advances map[rune]fixed.Int26_6
+
+	// shapes caches ShapeCached results by string.
+	shapes map[string][]GlyphPlacement
+
+	// ascii holds a *[asciiGlyphs]*Character indexed by rune-' ' when the
+	// font is monospaced and covers all of printable ASCII, letting lookup
+	// skip CharMap for the most common text.
+	ascii atomic.Value
+
+	// asciiAdvances holds a *[asciiGlyphs]asciiAdvance indexed by rune-' ',
+	// so that printable ASCII is measured without locking.
+	asciiAdvances atomic.Value
+}
+
+const asciiGlyphs = '~' - ' ' + 1
+
+type asciiAdvance struct {
+	advance fixed.Int26_6
+	ok      bool
+}
+
+func newAdvanceCache() *advanceCache {
+	return &advanceCache{
+		advances: make(map[rune]fixed.Int26_6),
+		shapes:   make(map[string][]GlyphPlacement),
+	}
+}
+
+func (f *Font) advance(r rune) (fixed.Int26_6, bool) {
+	if f.advances == nil {
+		return f.uncachedAdvance(r)
+	}
+
+	if r >= ' ' && r <= '~' {
+		if table, _ := f.advances.asciiAdvances.Load().(*[asciiGlyphs]asciiAdvance); table != nil {
+			e := table[r-' ']
+			return e.advance, e.ok
+		}
+	}
+
+	f.advances.mu.RLock()
+	a, ok := f.advances.advances[r]
+	f.advances.mu.RUnlock()
+	if ok {
+		return a, true
+	}
+
+	a, ok = f.uncachedAdvance(r)
+	if ok {
+		f.advances.mu.Lock()
+		f.advances.advances[r] = a
+		f.advances.mu.Unlock()
+	}
+	return a, ok
+}
+
+func (f *Font) uncachedAdvance(r rune) (fixed.Int26_6, bool) {
+	c := f.lookup(r)
+	if c == nil {
+		return 0, false
+	}
+	a := fixed.I(c.Advance[0])
+	if f.MetricsSet == 1 {
+		// Vertical-only fonts have no horizontal DWIDTH, so advance by the
+		// vertical one instead.
+		v := c.VerticalAdvance[1]
+		if v < 0 {
+			v = -v
+		}
+		a = fixed.I(v)
+	}
+	if px, ok := f.advanceOverrides[r]; ok {
+		a = fixed.I(px)
+	}
+
+	return a, true
+}
+
+// advance is faceAdvance plus the face's tracking.
+func (f *Face) advance(r rune) (fixed.Int26_6, bool) {
+	a, ok := f.faceAdvance(r)
+	if ok {
+		a += fixed.I(f.Tracking)
+	}
+	return a, ok
+}
+
+// faceAdvance is Font.advance with the face's rounding mode applied to
+// glyphs whose DWIDTH was fractional.
+func (f *Face) faceAdvance(r rune) (fixed.Int26_6, bool) {
+	a, ok := f.Font.advance(r)
+	if !ok && f.tofu != nil {
+		return fixed.I(f.tofu.Advance[0]), true
+	}
+	if !ok || f.RoundMode == RoundNearest || f.Font.MetricsSet == 1 {
+		return a, ok
+	}
+	if _, overridden := f.Font.advanceOverrides[r]; overridden {
+		return a, ok
+	}
+
+	c := f.Font.lookup(r)
+	exact := c.ExactAdvance[0]
+	if exact == float64(c.Advance[0]) {
+		return a, ok
+	}
+
+	switch f.RoundMode {
+	case RoundFloor:
+		a = fixed.I(int(math.Floor(exact)))
+	case RoundCeil:
+		a = fixed.I(int(math.Ceil(exact)))
+	}
+	return a, ok
+}
+
+// InvalidateCache drops the font's cached advances and shaped strings and
+// rebuilds its ASCII lookup table. The font's own methods call it as needed;
+// callers must call it after editing Characters, CharMap or advances
+// directly.
+func (f *Font) InvalidateCache() {
+	if f.advances == nil {
+		return
+	}
+	f.advances.mu.Lock()
+	f.advances.advances = make(map[rune]fixed.Int26_6)
+	f.advances.shapes = make(map[string][]GlyphPlacement)
+	f.advances.mu.Unlock()
+
+	f.buildASCII()
+}
+
+// buildASCII rebuilds the printable ASCII glyph and advance tables.
+func (f *Font) buildASCII() {
+	if f.advances == nil {
+		return
+	}
+
+	var table *[asciiGlyphs]*Character
+	if f.IsMonospace() {
+		table = new([asciiGlyphs]*Character)
+		for i := range table {
+			c, ok := f.CharMap[' '+rune(i)]
+			if !ok {
+				table = nil
+				break
+			}
+			table[i] = c
+		}
+	}
+	f.advances.ascii.Store(table)
+
+	// Advances go through lookup, so they are computed after the glyph
+	// table is replaced.
+	advances := new([asciiGlyphs]asciiAdvance)
+	for i := range advances {
+		a, ok := f.uncachedAdvance(' ' + rune(i))
+		advances[i] = asciiAdvance{advance: a, ok: ok}
+	}
+	f.advances.asciiAdvances.Store(advances)
+}
+
+func (f *Font) lookupASCII(r rune) *Character {
+	if f.advances == nil || r < ' ' || r > '~' {
+		return nil
+	}
+	table, _ := f.advances.ascii.Load().(*[asciiGlyphs]*Character)
+	if table == nil {
+		return nil
+	}
+	return table[r-' ']
+}
+
+// OverrideAdvance replaces the advance reported for r without changing the
+// parsed DWIDTH of its glyph.
+func (f *Font) OverrideAdvance(r rune, px int) {
+	if f.advanceOverrides == nil {
+		f.advanceOverrides = make(map[rune]int)
+	}
+	f.advanceOverrides[r] = px
+	f.InvalidateCache()
+}
+
+// MeasureString returns the width of s drawn with the face, including
+// kerning. Unlike font.MeasureString, which adds every glyph's advance,
+// tracking is counted between glyphs but not after the last one.
+func (f *Face) MeasureString(s string) fixed.Int26_6 {
+	var w fixed.Int26_6
+	prev, n := rune(-1), 0
+	for _, r := range s {
+		if prev >= 0 {
+			w += f.Kern(prev, r)
+		}
+		if a, ok := f.advance(f.remap(r)); ok {
+			w += a
+			n++
+		}
+		prev = r
+	}
+	if n > 0 {
+		w -= fixed.I(f.Tracking)
+	}
+	return w
+}
+
+// MeasureString returns the sum of the advances of the runes in s. Marks
+// with negative advances reduce the width, but it is never reported as
+// less than zero.
+func (f *Font) MeasureString(s string) fixed.Int26_6 {
+	var w fixed.Int26_6
+	for _, r := range s {
+		a, _ := f.advance(r)
+		w += a
+	}
+	if w < 0 {
+		return 0
+	}
+	return w
+}
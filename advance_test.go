@@ -0,0 +1,167 @@
+package bdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestMeasureString(t *testing.T) {
+	f := mustParse(t, testFont)
+	if got := f.MeasureString("AB g"); got != fixed.I(20) {
+		t.Errorf("MeasureString(%q) = %v, want 20", "AB g", got)
+	}
+	// A second call is answered from the cache and must agree.
+	if got := f.MeasureString("AB g"); got != fixed.I(20) {
+		t.Errorf("cached MeasureString(%q) = %v, want 20", "AB g", got)
+	}
+
+	m := mustParse(t, marksFont)
+	if got := m.MeasureString("A\u0301B"); got != fixed.I(8) {
+		t.Errorf("MeasureString(%q) = %v, want 8", "A\u0301B", got)
+	}
+}
+
+const measureText = "A BAg gAB BBA gg A"
+
+func BenchmarkMeasureString(b *testing.B) {
+	f := mustParse(b, testFont)
+	for i := 0; i < b.N; i++ {
+		f.MeasureString(measureText)
+	}
+}
+
+// BenchmarkMeasureStringUncached measures the same text on a font without
+// an advance cache, for comparison with BenchmarkMeasureString.
+func BenchmarkMeasureStringUncached(b *testing.B) {
+	f := mustParse(b, testFont)
+	f.advances = nil
+	for i := 0; i < b.N; i++ {
+		f.MeasureString(measureText)
+	}
+}
+
+func TestOverrideAdvance(t *testing.T) {
+	f := mustParse(t, testFont)
+	f.OverrideAdvance('A', 2)
+
+	if a, _ := f.NewFace().GlyphAdvance('A'); a != fixed.I(2) {
+		t.Errorf("GlyphAdvance('A') = %v, want 2", a)
+	}
+	if w := f.MeasureString("AB"); w != fixed.I(7) {
+		t.Errorf("MeasureString(\"AB\") = %v, want 7", w)
+	}
+}
+
+// asciiFont returns a monospaced font covering printable ASCII, which
+// enables the ASCII lookup table.
+func asciiFont(t testing.TB) *Font {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("STARTFONT 2.1\nFONT ascii\nSIZE 8 75 75\nFONT_ASCENT 6\nFONT_DESCENT 2\nCHARS 96\n")
+	for r := ' '; r <= '~'; r++ {
+		fmt.Fprintf(&b, "STARTCHAR c%d\nENCODING %d\nDWIDTH 5 0\nBBX 4 1 0 0\nBITMAP\nF0\nENDCHAR\n", r, r)
+	}
+	b.WriteString("STARTCHAR alt\nENCODING -1\nDWIDTH 5 0\nBBX 4 1 0 0\nBITMAP\n90\nENDCHAR\n")
+	b.WriteString("ENDFONT\n")
+	return mustParse(t, b.String())
+}
+
+func benchmarkGlyphs(b *testing.B, f *Font) {
+	face := f.NewFace()
+	dot := fixed.P(0, 6)
+	for i := 0; i < b.N; i++ {
+		for r := ' '; r <= '~'; r++ {
+			face.Glyph(dot, r)
+		}
+	}
+}
+
+func BenchmarkGlyphASCII(b *testing.B) {
+	benchmarkGlyphs(b, asciiFont(b))
+}
+
+// BenchmarkGlyphASCIIMap renders the same glyphs with the ASCII table
+// disabled, so every lookup goes through CharMap.
+func BenchmarkGlyphASCIIMap(b *testing.B) {
+	f := asciiFont(b)
+	f.advances.ascii.Store((*[asciiGlyphs]*Character)(nil))
+	benchmarkGlyphs(b, f)
+}
+
+func TestFaceMeasureStringTracking(t *testing.T) {
+	f := mustParse(t, testFont)
+	plain := &Face{Font: f}
+	tracked := &Face{Font: f, Tracking: 2}
+
+	const s = "ABgA"
+	if got, want := tracked.MeasureString(s), plain.MeasureString(s)+fixed.I(2*(len(s)-1)); got != want {
+		t.Errorf("tracked MeasureString = %v, want %v", got, want)
+	}
+	// font.MeasureString adds tracking after the last glyph as well.
+	if got, want := font.MeasureString(tracked, s), tracked.MeasureString(s)+fixed.I(2); got != want {
+		t.Errorf("font.MeasureString = %v, want %v", got, want)
+	}
+
+	f.Kerning = map[[2]rune]int{{'A', 'B'}: -1}
+	if got, want := tracked.MeasureString(s), font.MeasureString(tracked, s)-fixed.I(2); got != want {
+		t.Errorf("kerned MeasureString = %v, want %v", got, want)
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	f := mustParse(t, testFont)
+	face := f.NewFace()
+
+	if a, _ := face.GlyphAdvance('B'); a != fixed.I(5) {
+		t.Fatalf("GlyphAdvance('B') = %v, want 5", a)
+	}
+
+	f.CharMap['B'].Advance[0] = 10
+	if a, _ := face.GlyphAdvance('B'); a != fixed.I(5) {
+		t.Errorf("GlyphAdvance('B') before InvalidateCache = %v, want the cached 5", a)
+	}
+	f.InvalidateCache()
+	if a, _ := face.GlyphAdvance('B'); a != fixed.I(10) {
+		t.Errorf("GlyphAdvance('B') after InvalidateCache = %v, want 10", a)
+	}
+}
+
+func TestLookupASCIIFollowsCharMap(t *testing.T) {
+	f := asciiFont(t)
+	if f.lookupASCII('A') == nil {
+		t.Fatal("ASCII table not built for a monospaced ASCII font")
+	}
+
+	alt := &f.Characters[len(f.Characters)-1]
+	f.CharMap['A'] = alt
+	f.InvalidateCache()
+	if c := f.lookup('A'); c != alt {
+		t.Errorf("lookup('A') = %q after editing CharMap, want %q", c.Name, alt.Name)
+	}
+
+	f.Remove('B')
+	if c := f.lookup('B'); c != f.CharMap[f.DefaultChar] {
+		t.Errorf("lookup('B') after Remove = %v, want the default glyph", c)
+	}
+}
+
+func TestTransformsRefreshAdvances(t *testing.T) {
+	f := asciiFont(t)
+
+	for name, g := range map[string]*Font{
+		"DownscaleSmooth": f.DownscaleSmooth(4),
+		"ToMonospace":     f.ToMonospace(7),
+	} {
+		want := fixed.I(g.CharMap['A'].Advance[0])
+		if a, _ := g.NewFace().GlyphAdvance('A'); a != want {
+			t.Errorf("%s: GlyphAdvance('A') = %v, want the glyph's %v", name, a, want)
+		}
+		if w := g.MeasureString("AA"); w != 2*want {
+			t.Errorf("%s: MeasureString(\"AA\") = %v, want %v", name, w, 2*want)
+		}
+	}
+}
@@ -4,23 +4,35 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"image"
+	"io"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
 )
 
 type Character struct {
-	Name       string
-	Encoding   rune
-	Advance    [2]int
-	Alpha      *image.Alpha
-	LowerPoint [2]int
+	Name            string
+	Encoding        rune
+	RawEncoding     int
+	Advance         [2]int
+	ExactAdvance    [2]float64
+	VerticalAdvance [2]int
+	Alpha           *image.Alpha
+	LowerPoint      [2]int
 }
 
+// Font is a parsed BDF font. Glyph lookups and advances are cached, so
+// direct edits to Characters, CharMap or glyph advances must be followed by
+// a call to InvalidateCache.
 type Font struct {
 	Name        string
 	Size        int
@@ -35,21 +47,134 @@ type Font struct {
 	CharMap     map[rune]*Character
 	Encoding    string
 	DefaultChar rune
+
+	RelativeWeight int
+	SetWidthName   string
+	DeviceFontName string
+	QuadWidth      int
+
+	// MetricsSet is 0 for horizontal-only fonts, 1 for vertical-only fonts
+	// and 2 for fonts with both.
+	MetricsSet int
+
+	// Kerning holds pixel adjustments between pairs of runes, applied by
+	// Face.Kern and ShapeCached. ShapeCached does not notice direct edits
+	// until InvalidateCache is called.
+	Kerning map[[2]rune]int
+
+	Properties            map[string]string
+	DeclaredPropertyCount int
+	PropertyCount         int
+
+	advances         *advanceCache
+	advanceOverrides map[rune]int
+	lineHeight       int
+}
+
+type ParseOptions struct {
+	// Strict rejects fonts that are well-formed enough to parse but
+	// inconsistent with their own declarations.
+	Strict bool
+
+	// SkipCharMap leaves CharMap nil and only fills Characters, which saves
+	// memory when enumerating glyphs. Faces created from such a font find no
+	// glyphs.
+	SkipCharMap bool
+
+	// OnlyRunes, when non-nil, restricts parsing to glyphs for these runes.
+	// Other glyphs are dropped without decoding their bitmaps.
+	OnlyRunes []rune
+
+	// OnLine, when set, is called with every line of the file, numbered
+	// from 1, before it is parsed.
+	OnLine func(lineNo int, raw string)
+
+	// SkipBadGlyphs drops glyphs that fail to parse instead of failing the
+	// whole font. The font is then returned together with a GlyphErrors
+	// describing each dropped glyph.
+	SkipBadGlyphs bool
+
+	// ForceDPI, when non-zero, replaces the resolution declared by the
+	// font. Size is then recomputed from PIXEL_SIZE where the font has one.
+	ForceDPI [2]int
+
+	// MaxBytes, when positive, rejects input longer than this many bytes
+	// with ErrTooLarge.
+	MaxBytes int64
+}
+
+// onLineSplitter splits lines like bufio.ScanLines, reporting each to fn.
+func onLineSplitter(fn func(lineNo int, raw string)) bufio.SplitFunc {
+	lineNo := 0
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			lineNo++
+			fn(lineNo, string(token))
+		}
+		return advance, token, err
+	}
 }
 
 type Face struct {
 	Font *Font
+
+	// AutoKern enables kerning derived from the glyphs' ink side bearings.
+	AutoKern bool
+
+	// Remap substitutes runes before they are looked up in the font.
+	Remap map[rune]rune
+
+	// RoundMode selects how fractional DWIDTH advances become whole pixels.
+	RoundMode RoundMode
+
+	// Tracking adds this many pixels to every glyph's advance, as returned
+	// by Glyph, GlyphBounds and GlyphAdvance.
+	Tracking int
+
+	// StrokeWidth thickens glyphs returned by Glyph by dilating their masks
+	// this many pixels in every direction, for a faux bold. Advances are
+	// unchanged.
+	StrokeWidth int
+
+	// tofu, when set, stands in for runes the font cannot render.
+	tofu *Character
 }
 
+type RoundMode int
+
+const (
+	RoundNearest RoundMode = iota
+	RoundFloor
+	RoundCeil
+)
+
 func (f *Font) NewFace() font.Face {
+	if f == nil {
+		f = &Font{}
+	}
 	return &Face{
 		Font: f,
 	}
 }
 
+func (f *Font) NewFaceWithRemap(remap map[rune]rune) font.Face {
+	return &Face{
+		Font:  f,
+		Remap: remap,
+	}
+}
+
 func (f *Font) lookup(r rune) *Character {
+	if c := f.lookupASCII(r); c != nil {
+		return c
+	}
+
 	c, ok := f.CharMap[r]
 	if !ok {
+		if f.DefaultChar < 0 {
+			return nil
+		}
 		c, ok = f.CharMap[f.DefaultChar]
 		if !ok {
 			return nil
@@ -58,86 +183,249 @@ func (f *Font) lookup(r rune) *Character {
 	return c
 }
 
-func parseGlobalsAndProperties(s *bufio.Scanner, f *Font) error {
-	var err error
+func (f *Font) sortedRunes() []rune {
+	runes := make([]rune, 0, len(f.CharMap))
+	for r := range f.CharMap {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
 
-	var registry string
-	var encoding string
-	var defaultChar int
+// Encodings returns the runes in CharMap in ascending order.
+func (f *Font) Encodings() []rune {
+	return f.sortedRunes()
+}
 
-scan:
-	for s.Scan() {
-		components := strings.Split(s.Text(), " ")
-		switch components[0] {
-		case "FONT":
-			f.Name = components[1]
-		case "SIZE":
-			f.Size, err = strconv.Atoi(components[1])
-			if err != nil {
-				return err
+// All returns an iterator over the encoded characters in order of encoding.
+// Its type matches iter.Seq[*Character], so with Go 1.23 or later it can be
+// used as `for c := range f.All()`.
+func (f *Font) All() func(yield func(*Character) bool) {
+	return func(yield func(*Character) bool) {
+		for _, r := range f.sortedRunes() {
+			if !yield(f.CharMap[r]) {
+				return
 			}
+		}
+	}
+}
 
-			f.DPI[0], err = strconv.Atoi(components[2])
-			if err != nil {
-				return err
-			}
+// NotdefGlyph returns the glyph used in place of runes missing from the font.
+func (f *Font) NotdefGlyph() (*Character, bool) {
+	c, ok := f.CharMap[f.DefaultChar]
+	return c, ok
+}
 
-			f.DPI[1], err = strconv.Atoi(components[3])
-			if err != nil {
-				return err
-			}
+// CharacterByCode finds a glyph by the code in its ENCODING line rather than
+// by the decoded rune.
+func (f *Font) CharacterByCode(code int) (*Character, bool) {
+	if code < 0 {
+		return nil, false
+	}
+	for i := range f.Characters {
+		if f.Characters[i].RawEncoding == code {
+			return &f.Characters[i], true
+		}
+	}
+	return nil, false
+}
 
-			if len(components) > 4 {
-				f.BPP, err = strconv.Atoi(components[4])
-				if err != nil {
-					return err
-				}
-			}
-		case "CHARSET_REGISTRY":
-			registry = components[1]
-		case "CHARSET_ENCODING":
-			encoding = components[1]
-		case "PIXEL_SIZE":
-			f.PixelSize, err = strconv.Atoi(components[1])
-		case "FONT_ASCENT":
-			f.Ascent, err = strconv.Atoi(components[1])
-			if err != nil {
-				return err
-			}
-		case "FONT_DESCENT":
-			f.Descent, err = strconv.Atoi(components[1])
-			if err != nil {
-				return err
-			}
-		case "CAP_HEIGHT":
-			f.CapHeight, err = strconv.Atoi(components[1])
-			if err != nil {
-				return err
-			}
-		case "X_HEIGHT":
-			f.XHeight, err = strconv.Atoi(components[1])
+// HasGlyph reports whether CharMap has a glyph for r, without falling back
+// to DefaultChar.
+func (f *Font) HasGlyph(r rune) bool {
+	_, ok := f.CharMap[r]
+	return ok
+}
+
+// DecodeString maps each UTF-8 encoded rune in b to its glyph, substituting
+// the DefaultChar glyph for missing runes. Entries are nil when neither exists.
+func (f *Font) DecodeString(b []byte) []*Character {
+	chars := make([]*Character, 0, utf8.RuneCount(b))
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		chars = append(chars, f.lookup(r))
+		b = b[size:]
+	}
+	return chars
+}
+
+type globals struct {
+	opts         ParseOptions
+	registry     string
+	encoding     string
+	defaultChar  int
+	inProperties bool
+	sizeBPP      bool
+
+	// widths holds font-level DWIDTH and DWIDTH1 defaults for glyphs.
+	widths Character
+}
+
+func (g *globals) apply(f *Font) {
+	f.Encoding = g.registry + "-" + g.encoding
+	if g.defaultChar < 0 {
+		// DEFAULT_CHAR -1 means the font has no default glyph.
+		f.DefaultChar = -1
+	} else {
+		f.DefaultChar = charToRune(f.Encoding, g.defaultChar)
+	}
+
+	forced := g.opts.ForceDPI != [2]int{}
+	if forced {
+		f.DPI = g.opts.ForceDPI
+	}
+
+	if (f.Size == 0 || forced) && f.PixelSize > 0 {
+		dpi := f.DPI[1]
+		if dpi <= 0 {
+			dpi = 72
+		}
+		f.Size = (f.PixelSize*72 + dpi/2) / dpi
+	}
+}
+
+// propertyValue returns everything after the keyword, with surrounding
+// quotes removed and BDF's doubled quotes ("") unescaped.
+func propertyValue(components []string) string {
+	v := strings.Join(components[1:], " ")
+	if len(v) >= 2 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) {
+		v = strings.ReplaceAll(v[1:len(v)-1], `""`, `"`)
+	}
+	return v
+}
+
+func parseGlobal(f *Font, g *globals, components []string) error {
+	var err error
+
+	if g.inProperties && components[0] != "ENDPROPERTIES" && components[0] != "COMMENT" {
+		f.PropertyCount++
+		f.Properties[components[0]] = propertyValue(components)
+	}
+
+	switch components[0] {
+	case "STARTPROPERTIES":
+		f.DeclaredPropertyCount, err = strconv.Atoi(components[1])
+		g.inProperties = true
+	case "ENDPROPERTIES":
+		g.inProperties = false
+		if g.opts.Strict && f.PropertyCount != f.DeclaredPropertyCount {
+			return fmt.Errorf("%w: declared %d, found %d", ErrPropertyCountMismatch, f.DeclaredPropertyCount, f.PropertyCount)
+		}
+	case "FONT":
+		f.Name = propertyValue(components)
+	case "SIZE":
+		f.Size, err = strconv.Atoi(components[1])
+		if err != nil {
+			return err
+		}
+
+		f.DPI[0], err = strconv.Atoi(components[2])
+		if err != nil {
+			return err
+		}
+
+		f.DPI[1], err = strconv.Atoi(components[3])
+		if err != nil {
+			return err
+		}
+
+		if len(components) > 4 {
+			f.BPP, err = strconv.Atoi(components[4])
 			if err != nil {
 				return err
 			}
-		case "DEFAULT_CHAR":
-			defaultChar, err = strconv.Atoi(components[1])
+			g.sizeBPP = true
+		}
+	case "BITS_PER_PIXEL":
+		// The SIZE line's bit depth, when present, takes precedence.
+		if !g.sizeBPP {
+			f.BPP, err = strconv.Atoi(components[1])
+		}
+	case "METRICSSET":
+		f.MetricsSet, err = strconv.Atoi(components[1])
+	case "CHARSET_REGISTRY":
+		g.registry = propertyValue(components)
+	case "CHARSET_ENCODING":
+		g.encoding = propertyValue(components)
+	case "PIXEL_SIZE":
+		f.PixelSize, err = strconv.Atoi(components[1])
+	case "FONT_ASCENT":
+		f.Ascent, err = strconv.Atoi(components[1])
+	case "FONT_DESCENT":
+		f.Descent, err = strconv.Atoi(components[1])
+	case "CAP_HEIGHT":
+		f.CapHeight, err = strconv.Atoi(components[1])
+	case "X_HEIGHT":
+		f.XHeight, err = strconv.Atoi(components[1])
+	case "RELATIVE_WEIGHT":
+		f.RelativeWeight, err = strconv.Atoi(components[1])
+	case "SETWIDTH_NAME":
+		f.SetWidthName = propertyValue(components)
+	case "DEVICE_FONTNAME":
+		f.DeviceFontName = propertyValue(components)
+	case "QUAD_WIDTH":
+		f.QuadWidth, err = strconv.Atoi(components[1])
+	case "DEFAULT_CHAR":
+		g.defaultChar, err = strconv.Atoi(components[1])
+	case "DWIDTH", "DWIDTH1":
+		if !g.inProperties {
+			err = parseWidth(&g.widths, components)
+		}
+	}
+
+	return err
+}
+
+// parseWidth reads a DWIDTH or DWIDTH1 line into c's advances.
+func parseWidth(c *Character, components []string) error {
+	for i := 0; i < 2 && i+1 < len(components); i++ {
+		if components[0] == "DWIDTH1" {
+			v, err := strconv.Atoi(components[i+1])
 			if err != nil {
 				return err
 			}
-		case "CHARS":
+			c.VerticalAdvance[i] = v
+			continue
+		}
+
+		v, err := strconv.ParseFloat(components[i+1], 64)
+		if err != nil {
+			return err
+		}
+		c.ExactAdvance[i] = v
+		c.Advance[i] = int(math.Round(v))
+	}
+	return nil
+}
+
+// splitLine splits a line into space-separated fields, ignoring any
+// indentation left by hand editing.
+func splitLine(line string) []string {
+	return strings.Split(strings.TrimLeft(line, " \t"), " ")
+}
+
+func parseGlobalsAndProperties(s *bufio.Scanner, f *Font, opts ParseOptions) (*globals, error) {
+	g := &globals{opts: opts}
+
+	for s.Scan() {
+		components := splitLine(s.Text())
+		if components[0] == "CHARS" {
 			count, err := strconv.Atoi(components[1])
 			if err != nil {
-				return err
+				return nil, err
 			}
 			f.Characters = make([]Character, count)
-			break scan
+			break
+		}
+
+		if err := parseGlobal(f, g, components); err != nil {
+			return nil, err
 		}
 	}
 
-	f.Encoding = registry + "-" + encoding
-	f.DefaultChar = charToRune(f.Encoding, defaultChar)
+	g.apply(f)
 
-	return nil
+	return g, nil
 }
 
 func charToRune(encoding string, char int) rune {
@@ -147,170 +435,579 @@ func charToRune(encoding string, char int) rune {
 	return rune(char)
 }
 
+var knownCharmaps = map[string]*charmap.Charmap{
+	"iso8859-1":   charmap.ISO8859_1,
+	"iso8859-2":   charmap.ISO8859_2,
+	"iso8859-9":   charmap.ISO8859_9,
+	"iso8859-15":  charmap.ISO8859_15,
+	"apple-roman": charmap.Macintosh,
+	"mac-roman":   charmap.Macintosh,
+}
+
 func findCharmap(requested string) *charmap.Charmap {
 	trimmed := strings.TrimSpace(strings.ToLower(requested))
 
-	knownMaps := map[string]*charmap.Charmap{
-		"iso8859-1":  charmap.ISO8859_1,
-		"iso8859-2":  charmap.ISO8859_2,
-		"iso8859-9":  charmap.ISO8859_9,
-		"iso8859-15": charmap.ISO8859_15,
+	charMap := knownCharmaps[trimmed]
+	if charMap == nil && strings.HasSuffix(trimmed, "-0") {
+		// CHARSET_ENCODING "0" names the registry's base charset, so try the
+		// registry alone and then its first part.
+		registry := strings.TrimSuffix(trimmed, "-0")
+		if charMap = knownCharmaps[registry]; charMap == nil {
+			charMap = knownCharmaps[registry+"-1"]
+		}
 	}
-
-	charMap := knownMaps[trimmed]
 	return charMap
 }
 
+// knownEncoding reports whether glyph codes in encoding can be mapped to
+// runes, either through a known charmap or because they already are Unicode.
+func knownEncoding(encoding string) bool {
+	trimmed := strings.TrimSpace(strings.ToLower(encoding))
+	return trimmed == "-" || trimmed == "iso10646-1" || findCharmap(trimmed) != nil
+}
+
+// IsUnicode reports whether glyph codes are already Unicode code points,
+// meaning the font's ISO 10646 registry is used and no charmap decoding
+// takes place.
+func (f *Font) IsUnicode() bool {
+	trimmed := strings.TrimSpace(strings.ToLower(f.Encoding))
+	return strings.HasPrefix(trimmed, "iso10646-") || strings.HasPrefix(trimmed, "unicode-")
+}
+
+// charmapName returns the first name, in sorted order, under which target
+// is known.
+func charmapName(target *charmap.Charmap) (string, bool) {
+	names := make([]string, 0, len(knownCharmaps))
+	for name := range knownCharmaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if knownCharmaps[name] == target {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ReEncode rewrites each glyph's RawEncoding to its byte in target and
+// rebuilds CharMap. It fails without modifying the font if target is not one
+// of the charsets the parser recognizes or if any encoded glyph cannot be
+// represented in target.
+func (f *Font) ReEncode(target *charmap.Charmap) error {
+	name, ok := charmapName(target)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEncoding, target)
+	}
+	if findCharmap(f.Encoding) == target {
+		// Keep the font's own spelling of the charset.
+		name = f.Encoding
+	}
+
+	codes := make(map[*Character]byte, len(f.CharMap))
+	for r, c := range f.CharMap {
+		b, ok := target.EncodeRune(r)
+		if !ok {
+			return fmt.Errorf("bdf: glyph %q (U+%04X) has no encoding in %s", c.Name, r, target)
+		}
+		codes[c] = b
+	}
+
+	charMap := make(map[rune]*Character, len(codes))
+	for i := range f.Characters {
+		c := &f.Characters[i]
+		b, ok := codes[c]
+		if !ok {
+			continue
+		}
+		c.RawEncoding = int(b)
+		c.Encoding = target.DecodeByte(b)
+		charMap[c.Encoding] = c
+	}
+
+	f.Encoding = name
+	f.CharMap = charMap
+	f.InvalidateCache()
+
+	return nil
+}
+
+// DecodeWith re-keys CharMap by decoding each glyph's RawEncoding, taken as
+// a big-endian byte sequence, with enc. Glyphs whose code enc cannot map are
+// left unencoded, and glyphs with an ENCODING of -1 keep their rune.
+func (f *Font) DecodeWith(enc encoding.Encoding) error {
+	def := f.lookup(f.DefaultChar)
+	dec := enc.NewDecoder()
+
+	charMap := make(map[rune]*Character)
+	for i := range f.Characters {
+		c := &f.Characters[i]
+		if c.RawEncoding < 0 {
+			if c.Encoding >= 0 {
+				charMap[c.Encoding] = c
+			}
+			continue
+		}
+
+		var b []byte
+		for code := c.RawEncoding; code > 0 || len(b) == 0; code >>= 8 {
+			b = append([]byte{byte(code)}, b...)
+		}
+
+		decoded, err := dec.Bytes(b)
+		if err != nil {
+			return err
+		}
+
+		r, _ := utf8.DecodeRune(decoded)
+		if r == utf8.RuneError {
+			// Decoders substitute U+FFFD for codes they cannot map.
+			c.Encoding = -1
+			continue
+		}
+		c.Encoding = r
+		charMap[r] = c
+	}
+
+	f.CharMap = charMap
+	if def != nil {
+		f.DefaultChar = def.Encoding
+	}
+	f.InvalidateCache()
+
+	return nil
+}
+
 func bitAt(xs []byte, i int) byte {
 	return (xs[i>>3] >> (7 - (i % 8))) & 1
 }
 
 func Parse(data []byte) (*Font, error) {
+	return ParseWithOptions(data, ParseOptions{})
+}
+
+func ParseReader(r io.Reader) (*Font, error) {
+	return ParseReaderWithOptions(r, ParseOptions{})
+}
+
+// ParseReaderWithOptions reads and parses a font from r. With MaxBytes set,
+// reading stops as soon as the limit is exceeded.
+func ParseReaderWithOptions(r io.Reader, opts ParseOptions) (*Font, error) {
+	if opts.MaxBytes > 0 {
+		r = io.LimitReader(r, opts.MaxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithOptions(data, opts)
+}
+
+func ParseWithOptions(data []byte, opts ParseOptions) (*Font, error) {
+	if opts.MaxBytes > 0 && int64(len(data)) > opts.MaxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, opts.MaxBytes)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
 	r := bytes.NewReader(data)
 	s := bufio.NewScanner(r)
+	if opts.OnLine != nil {
+		s.Split(onLineSplitter(opts.OnLine))
+	}
 
 	f := Font{
 		CharMap:     make(map[rune]*Character),
+		Properties:  make(map[string]string),
 		DefaultChar: 32,
 		BPP:         1,
+		advances:    newAdvanceCache(),
+	}
+	if opts.SkipCharMap {
+		f.CharMap = nil
 	}
 
-	var err error
-
-	err = parseGlobalsAndProperties(s, &f)
+	g, err := parseGlobalsAndProperties(s, &f, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	charMap := findCharmap(f.Encoding)
 
+	var only map[rune]bool
+	if opts.OnlyRunes != nil {
+		only = make(map[rune]bool, len(opts.OnlyRunes))
+		for _, r := range opts.OnlyRunes {
+			only[r] = true
+		}
+	}
+
+	glyphs := 0
 	char := -1
-	row := -1
+	var size [2]int
+	var bitmap []string
+	encoded := false
 	inBitmap := false
-	for s.Scan() {
-		components := strings.Split(s.Text(), " ")
+	skipping := false
+	open := false
+	var glyphErrs GlyphErrors
 
-		if !inBitmap {
-			switch components[0] {
+	// drop discards the current glyph and reuses its slot.
+	drop := func() {
+		if c, ok := f.CharMap[f.Characters[char].Encoding]; ok && c == &f.Characters[char] {
+			delete(f.CharMap, f.Characters[char].Encoding)
+		}
+		f.Characters[char] = Character{}
+		char--
+	}
 
-			case "STARTCHAR":
-				char++
-				f.Characters[char].Name = components[1]
-			case "ENCODING":
-				code, err := strconv.Atoi(components[1])
-				if err != nil {
-					return nil, err
-				}
+	// closeUnterminated drops a glyph left open by a missing ENDCHAR, which
+	// is an error unless the glyph was already being skipped.
+	closeUnterminated := func() error {
+		if !open {
+			return nil
+		}
+		open = false
+		inBitmap = false
+		if !skipping {
+			err := fmt.Errorf("glyph %q: %w: missing ENDCHAR", f.Characters[char].Name, ErrInvalidBitmap)
+			if !opts.SkipBadGlyphs {
+				return err
+			}
+			glyphErrs = append(glyphErrs, err)
+		}
+		skipping = false
+		drop()
+		return nil
+	}
 
-				var r rune
-				if charMap != nil {
-					r = charMap.DecodeByte(byte(code))
-				} else {
-					r = rune(code)
-				}
-				f.Characters[char].Encoding = r
-				f.CharMap[r] = &f.Characters[char]
-			case "DWIDTH":
-				f.Characters[char].Advance[0], err = strconv.Atoi(components[1])
-				if err != nil {
-					return nil, err
+	for s.Scan() {
+		components := splitLine(s.Text())
+		if skipping && components[0] != "ENDCHAR" && components[0] != "STARTCHAR" {
+			continue
+		}
+
+		var glyphErr error
+		switch components[0] {
+		case "STARTCHAR":
+			if char == -1 {
+				// Globals may also appear between CHARS and the first glyph.
+				g.apply(&f)
+				charMap = findCharmap(f.Encoding)
+			}
+			if err := closeUnterminated(); err != nil {
+				return nil, err
+			}
+			glyphs++
+			if glyphs > len(f.Characters) {
+				return nil, fmt.Errorf("%w: more than %d glyphs", ErrGlyphCountMismatch, len(f.Characters))
+			}
+			char++
+			open = true
+			f.Characters[char].Name = components[1]
+			f.Characters[char].Advance = g.widths.Advance
+			f.Characters[char].ExactAdvance = g.widths.ExactAdvance
+			f.Characters[char].VerticalAdvance = g.widths.VerticalAdvance
+			size = [2]int{}
+			bitmap = nil
+			encoded = false
+		case "ENCODING":
+			code, err := strconv.Atoi(components[1])
+			if err != nil {
+				glyphErr = err
+				break
+			}
+
+			f.Characters[char].RawEncoding = code
+			encoded = true
+			if code < 0 {
+				if len(components) < 3 {
+					f.indexByName(&f.Characters[char])
+					continue
 				}
 
-				f.Characters[char].Advance[1], err = strconv.Atoi(components[2])
+				// The optional second field is a font-specific encoding.
+				alt, err := strconv.Atoi(components[2])
 				if err != nil {
-					return nil, err
+					glyphErr = err
+					break
 				}
-			case "BBX":
-				w, err := strconv.Atoi(components[1])
-				if err != nil {
-					return nil, err
+				f.Characters[char].Encoding = rune(alt)
+				if f.CharMap != nil {
+					f.CharMap[rune(alt)] = &f.Characters[char]
 				}
+				continue
+			}
 
-				h, err := strconv.Atoi(components[2])
-				if err != nil {
+			var r rune
+			if charMap != nil {
+				r = charMap.DecodeByte(byte(code))
+			} else {
+				r = rune(code)
+			}
+			f.Characters[char].Encoding = r
+			if f.CharMap != nil {
+				f.CharMap[r] = &f.Characters[char]
+			}
+		case "DWIDTH", "DWIDTH1":
+			if char == -1 {
+				if err := parseGlobal(&f, g, components); err != nil {
 					return nil, err
 				}
+				break
+			}
+			glyphErr = parseWidth(&f.Characters[char], components)
+		case "BBX":
+			if len(components) < 5 {
+				glyphErr = fmt.Errorf("%w: %q", ErrInvalidBBX, s.Text())
+				break
+			}
 
-				// Lower-left corner?
-				lx, err := strconv.Atoi(components[3])
-				if err != nil {
-					return nil, err
-				}
-				ly, err := strconv.Atoi(components[4])
-				if err != nil {
-					return nil, err
-				}
+			w, err := strconv.Atoi(components[1])
+			if err != nil {
+				glyphErr = fmt.Errorf("%w: %v", ErrInvalidBBX, err)
+				break
+			}
 
-				f.Characters[char].LowerPoint[0] = lx
-				f.Characters[char].LowerPoint[1] = ly
-
-				f.Characters[char].Alpha = &image.Alpha{
-					Stride: w,
-					Rect: image.Rectangle{
-						Max: image.Point{
-							X: w,
-							Y: h,
-						},
-					},
-					Pix: make([]byte, w*h),
-				}
-			case "BITMAP":
-				inBitmap = true
-				row = -1
+			h, err := strconv.Atoi(components[2])
+			if err != nil {
+				glyphErr = fmt.Errorf("%w: %v", ErrInvalidBBX, err)
+				break
 			}
-		} else {
-			if components[0] == "ENDCHAR" {
-				inBitmap = false
-				continue
+
+			if w < 0 || h < 0 {
+				glyphErr = fmt.Errorf("%w: negative size %dx%d", ErrInvalidBBX, w, h)
+				break
 			}
 
-			row = row + 1
-			b, err := hex.DecodeString(s.Text())
+			// Lower-left corner?
+			lx, err := strconv.Atoi(components[3])
 			if err != nil {
-				return nil, err
+				glyphErr = fmt.Errorf("%w: %v", ErrInvalidBBX, err)
+				break
+			}
+			ly, err := strconv.Atoi(components[4])
+			if err != nil {
+				glyphErr = fmt.Errorf("%w: %v", ErrInvalidBBX, err)
+				break
+			}
+
+			f.Characters[char].LowerPoint[0] = lx
+			f.Characters[char].LowerPoint[1] = ly
+			size = [2]int{w, h}
+		case "CONTENTVERSION":
+			// Content versions, font-level or per glyph, are not tracked.
+		case "BITMAP":
+			inBitmap = true
+		case "ENDCHAR":
+			if !open {
+				// A stray ENDCHAR has no glyph to finish.
+				break
+			}
+			inBitmap = false
+			open = false
+			if skipping {
+				skipping = false
+				drop()
+				continue
+			}
+			if !encoded {
+				f.Characters[char].RawEncoding = -1
+				f.indexByName(&f.Characters[char])
+			}
+
+			if only != nil && !only[f.Characters[char].Encoding] {
+				// Drop the glyph without decoding it.
+				drop()
+				continue
 			}
 
-			for i := 0; i < f.Characters[char].Alpha.Stride; i++ {
-				val := byte(0x00)
-				for j := 0; j < f.BPP; j++ {
-					val <<= 1
-					val |= bitAt(b, i*f.BPP+j)
+			f.Characters[char].Alpha, glyphErr = f.decodeBitmap(size[0], size[1], bitmap)
+			if glyphErr != nil {
+				break
+			}
+			if opts.Strict && len(bitmap) != size[1] {
+				glyphErr = fmt.Errorf("%w: %d rows for BBX height %d", ErrInvalidBitmap, len(bitmap), size[1])
+			}
+		default:
+			if inBitmap {
+				bitmap = append(bitmap, strings.Join(components, ""))
+			} else if char == -1 {
+				if err := parseGlobal(&f, g, components); err != nil {
+					return nil, err
 				}
-				f.Characters[char].Alpha.Pix[row*f.Characters[char].Alpha.Stride+i] = byte(uint32(val) * 0xff / ((1 << f.BPP) - 1))
+			}
+		}
+
+		if glyphErr != nil {
+			glyphErr = fmt.Errorf("glyph %q: %w", f.Characters[char].Name, glyphErr)
+			if !opts.SkipBadGlyphs {
+				return nil, glyphErr
+			}
+			glyphErrs = append(glyphErrs, glyphErr)
+			if components[0] == "ENDCHAR" {
+				drop()
+			} else {
+				skipping = true
+				inBitmap = false
 			}
 		}
 	}
+	if err := closeUnterminated(); err != nil {
+		return nil, err
+	}
 
+	if opts.Strict && glyphs != len(f.Characters) {
+		return nil, fmt.Errorf("%w: declared %d, found %d", ErrGlyphCountMismatch, len(f.Characters), glyphs)
+	}
+	f.Characters = f.Characters[:char+1]
+	f.buildASCII()
+
+	if f.Ascent < 0 || f.Descent < 0 {
+		return nil, fmt.Errorf("%w: negative ascent %d or descent %d", ErrInvalidMetrics, f.Ascent, f.Descent)
+	}
+
+	if opts.Strict && !knownEncoding(f.Encoding) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, f.Encoding)
+	}
+
+	if len(glyphErrs) > 0 {
+		return &f, glyphErrs
+	}
 	return &f, nil
 }
 
+// indexByName adds a glyph without a usable ENCODING to CharMap using its
+// name, leaving it unencoded if the name does not identify a rune.
+func (f *Font) indexByName(c *Character) {
+	r, ok := c.NameToRune()
+	if !ok {
+		c.Encoding = -1
+		return
+	}
+	c.Encoding = r
+	if f.CharMap != nil {
+		f.CharMap[r] = c
+	}
+}
+
+func (f *Font) decodeBitmap(w, h int, hexRows []string) (*image.Alpha, error) {
+	rowBytes := (w*f.BPP + 7) / 8
+	rows := make([][]byte, len(hexRows))
+	for i, row := range hexRows {
+		b, err := hex.DecodeString(strings.TrimRight(row, "\r"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidBitmap, i, err)
+		}
+		// Some fonts pad rows to the font bounding box; the surplus bytes
+		// are ignored.
+		if len(b) < rowBytes {
+			return nil, fmt.Errorf("%w: row %d has %d bytes, want %d for width %d", ErrInvalidBitmap, i, len(b), rowBytes, w)
+		}
+		rows[i] = b
+	}
+
+	alpha := &image.Alpha{
+		Stride: w,
+		Rect: image.Rectangle{
+			Max: image.Point{
+				X: w,
+				Y: h,
+			},
+		},
+		Pix: make([]byte, w*h),
+	}
+
+	for row, b := range rows {
+		if row >= h {
+			break
+		}
+
+		for i := 0; i < w; i++ {
+			val := byte(0x00)
+			for j := 0; j < f.BPP; j++ {
+				val <<= 1
+				val |= bitAt(b, i*f.BPP+j)
+			}
+			alpha.Pix[row*w+i] = byte(uint32(val) * 0xff / ((1 << f.BPP) - 1))
+		}
+	}
+
+	return alpha, nil
+}
+
 func (f *Face) Close() error { return nil }
 
 func (f *Face) Metrics() font.Metrics {
+	// Never report a zero line height, even for an empty font, since callers
+	// commonly divide by it.
+	height := f.Font.LineHeight()
+	if height <= 0 {
+		height = f.Font.PixelSize
+	}
+	if height <= 0 {
+		height = 1
+	}
+
 	return font.Metrics{
 		Ascent:    fixed.I(f.Font.Ascent),
 		Descent:   fixed.I(f.Font.Descent),
 		CapHeight: fixed.I(f.Font.CapHeight),
 		XHeight:   fixed.I(f.Font.XHeight),
-		Height:    fixed.I(f.Font.Ascent + f.Font.Descent),
+		Height:    fixed.I(height),
 	}
 }
 
-func (f *Face) Kern(_, _ rune) fixed.Int26_6 {
-	return 0
+func (f *Face) Kern(r0, r1 rune) fixed.Int26_6 {
+	r0, r1 = f.remap(r0), f.remap(r1)
+	if k, ok := f.Font.Kerning[[2]rune{r0, r1}]; ok {
+		return fixed.I(k)
+	}
+	if !f.AutoKern {
+		return 0
+	}
+	return fixed.I(f.Font.autoKern(r0, r1))
+}
+
+// lookup finds the glyph for r, falling back to the tofu box if the face
+// has one.
+func (f *Face) lookup(r rune) *Character {
+	if c := f.Font.lookup(r); c != nil {
+		return c
+	}
+	return f.tofu
+}
+
+func (f *Face) remap(r rune) rune {
+	if to, ok := f.Remap[r]; ok {
+		return to
+	}
+	return r
 }
 
 func (f *Face) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
-	c := f.Font.lookup(r)
+	r = f.remap(r)
+	c := f.lookup(r)
 	if c == nil {
 		return image.Rectangle{}, nil, image.Point{}, 0, false
 	}
 
 	mask = c.Alpha
+	dr = glyphRect(c, dot)
+	if f.StrokeWidth > 0 {
+		mask = dilate(c.Alpha, f.StrokeWidth)
+		dr = dr.Inset(-f.StrokeWidth)
+	}
+
+	advance, _ = f.advance(r)
+
+	return dr, mask, image.Point{Y: 0}, advance, true
+}
 
+func glyphRect(c *Character, dot fixed.Point26_6) image.Rectangle {
 	x := int(dot.X)>>6 + c.LowerPoint[0]
 	y := int(dot.Y)>>6 - c.LowerPoint[1]
-	dr = image.Rectangle{
+	return image.Rectangle{
 		Min: image.Point{
 			X: x,
 			Y: y - c.Alpha.Rect.Max.Y,
@@ -320,23 +1017,53 @@ func (f *Face) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask imag
 			Y: y,
 		},
 	}
+}
 
-	return dr, mask, image.Point{Y: 0}, fixed.I(c.Advance[0]), true
+// GlyphInto copies the glyph for r into dst with its origin at dot and returns
+// the rectangle it covers. The glyph overwrites the pixels of dst rather than
+// being composited over them, and the face's StrokeWidth is ignored.
+func (f *Face) GlyphInto(dst *image.Alpha, dot fixed.Point26_6, r rune) (image.Rectangle, bool) {
+	r = f.remap(r)
+	c := f.lookup(r)
+	if c == nil {
+		return image.Rectangle{}, false
+	}
+
+	dr := glyphRect(c, dot)
+	clip := dr.Intersect(dst.Rect)
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		src := c.Alpha.PixOffset(clip.Min.X-dr.Min.X, y-dr.Min.Y)
+		copy(dst.Pix[dst.PixOffset(clip.Min.X, y):dst.PixOffset(clip.Max.X, y)], c.Alpha.Pix[src:src+clip.Dx()])
+	}
+
+	return dr, true
 }
 
 func (f *Face) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
-	c := f.Font.lookup(r)
+	r = f.remap(r)
+	c := f.lookup(r)
 	if c == nil {
 		return fixed.R(0, -f.Font.Ascent, 0, +f.Font.Descent), 0, false
 	}
 
-	return fixed.R(c.LowerPoint[0], -f.Font.Ascent, c.LowerPoint[0]+c.Alpha.Rect.Dx(), f.Font.Descent), fixed.I(c.Advance[0]), true
+	advance, _ = f.advance(r)
+	// The bounds are the glyph's BBX, flipped to y increasing downwards.
+	n := f.StrokeWidth
+	if n < 0 {
+		n = 0
+	}
+	bounds = fixed.R(
+		c.LowerPoint[0]-n,
+		-(c.LowerPoint[1]+c.Alpha.Rect.Dy())-n,
+		c.LowerPoint[0]+c.Alpha.Rect.Dx()+n,
+		-c.LowerPoint[1]+n,
+	)
+	return bounds, advance, true
 }
 
+// GlyphAdvance returns r's advance including the face's Tracking. Summing
+// these therefore counts tracking after the last glyph too; Face.MeasureString
+// leaves it out.
 func (f *Face) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
-	c := f.Font.lookup(r)
-	if c == nil {
-		return 0, false
-	}
-	return fixed.I(c.Advance[0]), true
+	return f.advance(f.remap(r))
 }
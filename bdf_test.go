@@ -0,0 +1,1154 @@
+package bdf
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// testFont is a small Unicode font with a space, two capitals and a letter
+// with a descender.
+const testFont = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--8-80-75-75-C-50-ISO10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 5 8 0 -2
+STARTPROPERTIES 6
+PIXEL_SIZE 8
+FONT_ASCENT 6
+FONT_DESCENT 2
+DEFAULT_CHAR 32
+CHARSET_REGISTRY "ISO10646"
+CHARSET_ENCODING "1"
+ENDPROPERTIES
+CHARS 4
+STARTCHAR space
+ENCODING 32
+SWIDTH 500 0
+DWIDTH 5 0
+BBX 5 8 0 -2
+BITMAP
+00
+00
+00
+00
+00
+00
+00
+00
+ENDCHAR
+STARTCHAR A
+ENCODING 65
+SWIDTH 500 0
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+60
+90
+90
+F0
+90
+90
+ENDCHAR
+STARTCHAR B
+ENCODING 66
+SWIDTH 500 0
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+E0
+90
+E0
+90
+90
+E0
+ENDCHAR
+STARTCHAR g
+ENCODING 103
+SWIDTH 500 0
+DWIDTH 5 0
+BBX 4 6 0 -2
+BITMAP
+70
+90
+90
+70
+10
+60
+ENDCHAR
+ENDFONT
+`
+
+func mustParse(t testing.TB, src string) *Font {
+	t.Helper()
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return f
+}
+
+// editGlyph returns src with the block of the glyph named name, from its
+// STARTCHAR line up to but excluding ENDCHAR, passed through edit.
+func editGlyph(src, name string, edit func(block string) string) string {
+	start := strings.Index(src, "STARTCHAR "+name+"\n")
+	end := start + strings.Index(src[start:], "ENDCHAR\n")
+	return src[:start] + edit(src[start:end]) + src[end:]
+}
+
+// replaceRows applies fn to each bitmap row in a glyph block.
+func replaceRows(fn func(row string) string) func(string) string {
+	return func(block string) string {
+		i := strings.Index(block, "BITMAP\n") + len("BITMAP\n")
+		rows := strings.SplitAfter(block[i:], "\n")
+		for j, row := range rows {
+			if row != "" {
+				rows[j] = fn(strings.TrimSuffix(row, "\n")) + "\n"
+			}
+		}
+		return block[:i] + strings.Join(rows, "")
+	}
+}
+
+func TestParse(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	if f.Name != "-test-fixed-medium-r-normal--8-80-75-75-C-50-ISO10646-1" {
+		t.Errorf("Name = %q", f.Name)
+	}
+	if f.Ascent != 6 || f.Descent != 2 || f.PixelSize != 8 {
+		t.Errorf("Ascent, Descent, PixelSize = %d, %d, %d, want 6, 2, 8", f.Ascent, f.Descent, f.PixelSize)
+	}
+	if len(f.Characters) != 4 || len(f.CharMap) != 4 {
+		t.Fatalf("got %d characters and %d CharMap entries, want 4", len(f.Characters), len(f.CharMap))
+	}
+	for _, r := range "ABg " {
+		if c := f.CharMap[r]; c == nil || c.Alpha == nil {
+			t.Errorf("CharMap[%q] has no bitmap", r)
+		}
+	}
+}
+
+// inkString renders one row of a glyph as '#' for ink and '.' for
+// background.
+func inkString(c *Character, row int) string {
+	var b strings.Builder
+	for x := 0; x < c.Alpha.Rect.Dx(); x++ {
+		if c.Alpha.AlphaAt(x, row).A != 0 {
+			b.WriteByte('#')
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+func TestParseGroupedRows(t *testing.T) {
+	src := editGlyph(testFont, "A", func(block string) string {
+		block = strings.Replace(block, "BBX 4 6 0 0", "BBX 16 6 0 0", 1)
+		return replaceRows(func(row string) string {
+			return row + " 0" + row[:1]
+		})(block)
+	})
+	f := mustParse(t, src)
+
+	want := []string{
+		".##..........##.",
+		"#..#........#..#",
+		"#..#........#..#",
+		"####........####",
+		"#..#........#..#",
+		"#..#........#..#",
+	}
+	for y, w := range want {
+		if got := inkString(f.CharMap['A'], y); got != w {
+			t.Errorf("row %d = %s, want %s", y, got, w)
+		}
+	}
+}
+
+// drawString draws s with face onto a new 32x8 image, starting at the
+// baseline's left end.
+func drawString(face font.Face, s string) *image.Alpha {
+	dst := image.NewAlpha(image.Rect(0, 0, 32, 8))
+	d := font.Drawer{Dst: dst, Src: image.Opaque, Face: face, Dot: fixed.P(0, 6)}
+	d.DrawString(s)
+	return dst
+}
+
+func TestGlyphInto(t *testing.T) {
+	f := mustParse(t, testFont)
+	face := f.NewFace().(*Face)
+
+	dst := image.NewAlpha(image.Rect(0, 0, 32, 8))
+	dot := fixed.P(0, 6)
+	for _, r := range "AB g" {
+		dr, ok := face.GlyphInto(dst, dot, r)
+		want, _, _, _, _ := face.Glyph(dot, r)
+		if !ok || dr != want {
+			t.Errorf("GlyphInto(%q) = %v, %v, want %v", r, dr, ok, want)
+		}
+		a, _ := face.GlyphAdvance(r)
+		dot.X += a
+	}
+
+	if want := drawString(face, "AB g"); !bytes.Equal(dst.Pix, want.Pix) {
+		t.Error("GlyphInto output differs from font.Drawer")
+	}
+
+	// Glyphs partly outside dst are clipped.
+	small := image.NewAlpha(image.Rect(0, 0, 2, 2))
+	if _, ok := face.GlyphInto(small, fixed.P(-1, 6), 'A'); !ok {
+		t.Error("GlyphInto into a clipped image failed")
+	}
+}
+
+func BenchmarkGlyphInto(b *testing.B) {
+	face := mustParse(b, testFont).NewFace().(*Face)
+	dst := image.NewAlpha(image.Rect(0, 0, 32, 8))
+	dot := fixed.P(0, 6)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		face.GlyphInto(dst, dot, 'A')
+	}
+}
+
+func BenchmarkGlyphDraw(b *testing.B) {
+	face := mustParse(b, testFont).NewFace()
+	dst := image.NewAlpha(image.Rect(0, 0, 32, 8))
+	dot := fixed.P(0, 6)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dr, mask, maskp, _, _ := face.Glyph(dot, 'A')
+		draw.DrawMask(dst, dr, image.Opaque, image.Point{}, mask, maskp, draw.Over)
+	}
+}
+
+func TestParseSingleFieldDWIDTH(t *testing.T) {
+	f := mustParse(t, editGlyph(testFont, "A", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0", "DWIDTH 7", 1)
+	}))
+
+	if a := f.CharMap['A'].Advance; a != [2]int{7, 0} {
+		t.Errorf("advance = %v, want [7 0]", a)
+	}
+}
+
+func TestDecodeWithShiftJIS(t *testing.T) {
+	// In Shift-JIS, 0x82A0 is HIRAGANA LETTER A.
+	f := mustParse(t, strings.Replace(testFont, "ENCODING 66", "ENCODING 33440", 1))
+	if err := f.DecodeWith(japanese.ShiftJIS); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := f.CharMap['あ']; c == nil || c.Name != "B" {
+		t.Errorf("0x82A0 not decoded to 'あ'")
+	}
+	if c := f.CharMap['A']; c == nil || c.Name != "A" {
+		t.Errorf("ASCII 'A' not kept")
+	}
+	if f.DefaultChar != ' ' {
+		t.Errorf("DefaultChar = %q, want ' '", f.DefaultChar)
+	}
+}
+
+func TestHasGlyph(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	if !f.HasGlyph('A') {
+		t.Error("HasGlyph('A') = false")
+	}
+	// 'Z' is drawn with the DefaultChar glyph but is not in the font.
+	if _, ok := f.NewFace().GlyphAdvance('Z'); !ok {
+		t.Error("GlyphAdvance('Z') found no fallback")
+	}
+	if f.HasGlyph('Z') {
+		t.Error("HasGlyph('Z') = true")
+	}
+}
+
+// marksFont has a space, two letters and two combining marks: a zero-width
+// grave with DWIDTH 0 and an acute that pulls the pen back two pixels.
+const marksFont = `STARTFONT 2.1
+FONT marks
+SIZE 8 75 75
+FONT_ASCENT 6
+FONT_DESCENT 2
+CHARSET_REGISTRY "ISO10646"
+CHARSET_ENCODING "1"
+CHARS 5
+STARTCHAR space
+ENCODING 32
+DWIDTH 5 0
+BBX 0 0 0 0
+BITMAP
+ENDCHAR
+STARTCHAR A
+ENCODING 65
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+60
+90
+90
+F0
+90
+90
+ENDCHAR
+STARTCHAR B
+ENCODING 66
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+E0
+90
+E0
+90
+90
+E0
+ENDCHAR
+STARTCHAR gravecomb
+ENCODING 768
+DWIDTH 0 0
+BBX 0 2 -3 6
+BITMAP
+00
+00
+ENDCHAR
+STARTCHAR acutecomb
+ENCODING 769
+DWIDTH -2 0
+BBX 2 2 -3 6
+BITMAP
+40
+80
+ENDCHAR
+ENDFONT
+`
+
+func TestNegativeAdvance(t *testing.T) {
+	face := mustParse(t, marksFont).NewFace()
+
+	if a, ok := face.GlyphAdvance('\u0301'); !ok || a != fixed.I(-2) {
+		t.Errorf("GlyphAdvance(U+0301) = %v, %v, want -2", a, ok)
+	}
+	// The acute pulls the pen back two pixels: 5 - 2 + 5.
+	if w := font.MeasureString(face, "A\u0301B"); w != fixed.I(8) {
+		t.Errorf("font.MeasureString = %v, want 8", w)
+	}
+	f := face.(*Face).Font
+	if w := f.MeasureString("A\u0301B"); w != fixed.I(8) {
+		t.Errorf("MeasureString = %v, want 8", w)
+	}
+	if w := f.MeasureString("\u0301"); w != 0 {
+		t.Errorf("MeasureString of a lone mark = %v, want 0", w)
+	}
+
+	// 'B' is drawn three pixels after 'A' instead of five.
+	want := image.NewAlpha(image.Rect(0, 0, 32, 8))
+	d := font.Drawer{Dst: want, Src: image.Opaque, Face: face, Dot: fixed.P(0, 6)}
+	d.DrawString("A")
+	d.Dot.X = fixed.I(3)
+	d.DrawString("B")
+	if got := drawString(face, "A\u0301B"); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("text after a negative-advance mark is misplaced")
+	}
+}
+
+func TestParseGlobalsAfterChars(t *testing.T) {
+	src := strings.Replace(testFont, "FONT_ASCENT 6\n", "", 1)
+	src = strings.Replace(src, "STARTPROPERTIES 6", "STARTPROPERTIES 5", 1)
+	src = strings.Replace(src, "CHARS 4\n", "CHARS 4\nFONT_ASCENT 6\n", 1)
+
+	if f := mustParse(t, src); f.Ascent != 6 {
+		t.Errorf("Ascent = %d, want 6", f.Ascent)
+	}
+}
+
+// TestParseVariants checks that layouts found in the wild parse to the same
+// glyphs as testFont.
+func TestParseVariants(t *testing.T) {
+	want := mustParse(t, testFont)
+
+	tests := map[string]string{
+		"BOM": "\xef\xbb\xbf" + testFont,
+		"indented": strings.NewReplacer(
+			"\nENCODING", "\n  ENCODING",
+			"\nDWIDTH", "\n\tDWIDTH",
+			"\nBBX", "\n \tBBX",
+			"\nBITMAP", "\n    BITMAP",
+		).Replace(testFont),
+		// bufio.ScanLines strips one CR before the LF, so the second
+		// one reaches the bitmap decoder.
+		"CRLF bitmap rows": editGlyph(testFont, "B", replaceRows(func(row string) string {
+			return row + "\r\r"
+		})),
+		"padded rows": editGlyph(testFont, "A", replaceRows(func(row string) string {
+			return row + "00"
+		})),
+		"CONTENTVERSION": editGlyph(testFont, "A", func(block string) string {
+			return strings.Replace(block, "ENCODING 65\n", "ENCODING 65\nCONTENTVERSION 3\n", 1)
+		}),
+		"scrambled": editGlyph(testFont, "A", func(string) string {
+			return "STARTCHAR A\nSWIDTH 500 0\nBITMAP\n60\n90\n90\nF0\n90\n90\nBBX 4 6 0 0\nDWIDTH 5 0\nENCODING 65\n"
+		}),
+	}
+
+	for name, src := range tests {
+		f, err := ParseWithOptions([]byte(src), ParseOptions{Strict: true})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for i := range want.Characters {
+			c := &want.Characters[i]
+			if got := f.CharMap[c.Encoding]; got == nil || !got.Equal(c) {
+				t.Errorf("%s: glyph %q differs", name, c.Name)
+			}
+		}
+	}
+}
+
+func TestParseGlyphNames(t *testing.T) {
+	f := mustParse(t, editGlyph(testFont, "A", func(block string) string {
+		block = strings.Replace(block, "STARTCHAR A", "STARTCHAR uni00C5", 1)
+		return strings.Replace(block, "ENCODING 65", "ENCODING -1", 1)
+	}))
+
+	if c := f.CharMap['Å']; c == nil || c.Name != "uni00C5" {
+		t.Error("uni00C5 not indexed by name")
+	}
+	if f.HasGlyph('A') {
+		t.Error("renamed glyph still indexed as 'A'")
+	}
+}
+
+func TestParseFractionalDWIDTH(t *testing.T) {
+	f := mustParse(t, editGlyph(testFont, "B", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0", "DWIDTH 6.5 0", 1)
+	}))
+
+	b := f.CharMap['B']
+	if b.Advance[0] != 7 || b.ExactAdvance[0] != 6.5 {
+		t.Errorf("Advance = %d, ExactAdvance = %v, want 7, 6.5", b.Advance[0], b.ExactAdvance[0])
+	}
+	if a := f.CharMap['A']; a.Advance[0] != 5 || a.ExactAdvance[0] != 5 {
+		t.Errorf("whole-pixel Advance = %d, ExactAdvance = %v, want 5, 5", a.Advance[0], a.ExactAdvance[0])
+	}
+}
+
+// dropLine returns src without the n-th (from 0) occurrence of line.
+func dropLine(src, line string, n int) string {
+	lines := strings.SplitAfter(src, "\n")
+	for i, l := range lines {
+		if strings.TrimRight(l, "\n") != line {
+			continue
+		}
+		if n == 0 {
+			return strings.Join(append(lines[:i:i], lines[i+1:]...), "")
+		}
+		n--
+	}
+	return src
+}
+
+func TestParseSize(t *testing.T) {
+	f := mustParse(t, dropLine(testFont, "SIZE 8 75 75", 0))
+	if f.Size != 8 || f.PixelSize != 8 {
+		t.Errorf("PIXEL_SIZE only: Size, PixelSize = %d, %d, want 8, 8", f.Size, f.PixelSize)
+	}
+
+	f, err := ParseWithOptions([]byte(testFont), ParseOptions{ForceDPI: [2]int{96, 96}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.DPI != [2]int{96, 96} || f.Size != 6 {
+		t.Errorf("ForceDPI: DPI, Size = %v, %d, want [96 96], 6", f.DPI, f.Size)
+	}
+}
+
+func TestParsePropertyCount(t *testing.T) {
+	f := mustParse(t, testFont)
+	if f.DeclaredPropertyCount != 6 || f.PropertyCount != 6 {
+		t.Errorf("property counts = %d declared, %d found, want 6", f.DeclaredPropertyCount, f.PropertyCount)
+	}
+
+	src := strings.Replace(testFont, "STARTPROPERTIES 6", "STARTPROPERTIES 7", 1)
+	if _, err := Parse([]byte(src)); err != nil {
+		t.Errorf("Parse with a miscounted STARTPROPERTIES: %v", err)
+	}
+	if _, err := ParseWithOptions([]byte(src), ParseOptions{Strict: true}); err == nil {
+		t.Errorf("strict ParseWithOptions accepted a miscounted STARTPROPERTIES")
+	}
+}
+
+func TestNotdefGlyph(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	c, ok := f.NotdefGlyph()
+	if !ok || c != f.CharMap[f.DefaultChar] || c.Name != "space" {
+		t.Errorf("NotdefGlyph() = %v, %v, want the space glyph", c, ok)
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden files")
+
+// checkGolden compares got with testdata/name, rewriting the file instead
+// when the -update flag is given.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s", path, got)
+	}
+}
+
+// latin1Font is an ISO 8859-1 font with an 'A' and an 'é'.
+const latin1Font = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--8-80-75-75-C-50-ISO8859-1
+SIZE 8 75 75
+FONT_ASCENT 6
+FONT_DESCENT 2
+CHARSET_REGISTRY "ISO8859"
+CHARSET_ENCODING "1"
+CHARS 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+60
+90
+90
+F0
+90
+90
+ENDCHAR
+STARTCHAR eacute
+ENCODING 233
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+20
+40
+60
+F0
+80
+70
+ENDCHAR
+ENDFONT
+`
+
+func TestReEncode(t *testing.T) {
+	f := mustParse(t, latin1Font)
+	if err := f.ReEncode(charmap.Macintosh); err != nil {
+		t.Fatalf("ReEncode: %v", err)
+	}
+	if f.Encoding != "apple-roman" {
+		t.Errorf("Encoding = %q, want apple-roman", f.Encoding)
+	}
+	if c := f.CharMap['é']; c == nil || c.RawEncoding != 0x8E {
+		t.Errorf("CharMap['é'] = %+v, want RawEncoding 0x8E", c)
+	}
+
+	f = mustParse(t, latin1Font)
+	if err := f.ReEncode(charmap.ISO8859_1); err != nil {
+		t.Fatalf("ReEncode: %v", err)
+	}
+	if f.Encoding != "ISO8859-1" {
+		t.Errorf("Encoding = %q, want the font's own ISO8859-1", f.Encoding)
+	}
+}
+
+func TestEmptyFontFace(t *testing.T) {
+	for name, face := range map[string]font.Face{
+		"empty": (&Font{}).NewFace(),
+		"nil":   (*Font)(nil).NewFace(),
+	} {
+		if h := face.Metrics().Height; h <= 0 {
+			t.Errorf("%s: Metrics().Height = %v, want positive", name, h)
+		}
+		if _, _, _, _, ok := face.Glyph(fixed.P(0, 0), 'A'); ok {
+			t.Errorf("%s: Glyph found a glyph", name)
+		}
+		if _, _, ok := face.GlyphBounds('A'); ok {
+			t.Errorf("%s: GlyphBounds found a glyph", name)
+		}
+		if _, ok := face.GlyphAdvance('A'); ok {
+			t.Errorf("%s: GlyphAdvance found a glyph", name)
+		}
+		if k := face.Kern('A', 'V'); k != 0 {
+			t.Errorf("%s: Kern = %v, want 0", name, k)
+		}
+	}
+}
+
+func TestParseMissingEncoding(t *testing.T) {
+	src := editGlyph(testFont, "A", func(block string) string {
+		block = strings.Replace(block, "STARTCHAR A", "STARTCHAR uni00C5", 1)
+		return strings.Replace(block, "ENCODING 65\n", "", 1)
+	})
+	src = editGlyph(src, "B", func(block string) string {
+		return strings.Replace(block, "ENCODING 66\n", "", 1)
+	})
+	f := mustParse(t, src)
+
+	if c := f.CharMap['Å']; c == nil || c.Name != "uni00C5" || c.RawEncoding != -1 {
+		t.Errorf("uni00C5 without ENCODING not indexed by name")
+	}
+	b := &f.Characters[2]
+	if b.Name != "B" || b.Encoding != -1 || b.RawEncoding != -1 {
+		t.Errorf("glyph without ENCODING = %q, %d, %d, want B, -1, -1", b.Name, b.Encoding, b.RawEncoding)
+	}
+	if c, ok := f.CharMap[0]; ok {
+		t.Errorf("glyph %q indexed at NUL", c.Name)
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	got := f.DecodeString([]byte("Aé g"))
+	want := []*Character{f.CharMap['A'], f.CharMap[' '], f.CharMap[' '], f.CharMap['g']}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeString = %v, want %v", got, want)
+	}
+
+	f = mustParse(t, strings.Replace(testFont, "DEFAULT_CHAR 32", "DEFAULT_CHAR -1", 1))
+	if got := f.DecodeString([]byte("é")); len(got) != 1 || got[0] != nil {
+		t.Errorf("DecodeString without DefaultChar = %v, want [nil]", got)
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	src := strings.Replace(testFont, "FONT -test-fixed-medium-r-normal--8-80-75-75-C-50-ISO10646-1", `FONT "Test Sans 8"`, 1)
+	src = strings.Replace(src, "STARTPROPERTIES 6", `STARTPROPERTIES 9
+COPYRIGHT "Acme ""Inc"""
+DEVICE_FONTNAME "TestSans-8"
+QUAD_WIDTH 5`, 1)
+	f, err := ParseWithOptions([]byte(src), ParseOptions{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Name != "Test Sans 8" {
+		t.Errorf("Name = %q, want %q", f.Name, "Test Sans 8")
+	}
+	if got := f.Properties["COPYRIGHT"]; got != `Acme "Inc"` {
+		t.Errorf("COPYRIGHT = %q, want %q", got, `Acme "Inc"`)
+	}
+	if f.DeviceFontName != "TestSans-8" || f.QuadWidth != 5 {
+		t.Errorf("DeviceFontName, QuadWidth = %q, %d, want %q, 5", f.DeviceFontName, f.QuadWidth, "TestSans-8")
+	}
+	if f.DeclaredPropertyCount != 9 || f.PropertyCount != 9 {
+		t.Errorf("property counts = %d declared, %d found, want 9", f.DeclaredPropertyCount, f.PropertyCount)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts ParseOptions
+		want error
+	}{
+		{"short BBX", strings.Replace(testFont, "BBX 4 6 0 0", "BBX 4 6 0", 1), ParseOptions{}, ErrInvalidBBX},
+		{"negative BBX", strings.Replace(testFont, "BBX 4 6 0 0", "BBX -4 6 0 0", 1), ParseOptions{}, ErrInvalidBBX},
+		{"extra glyph", strings.Replace(testFont, "CHARS 4", "CHARS 3", 1), ParseOptions{}, ErrGlyphCountMismatch},
+		{"property count", strings.Replace(testFont, "STARTPROPERTIES 6", "STARTPROPERTIES 7", 1), ParseOptions{Strict: true}, ErrPropertyCountMismatch},
+		{"unknown encoding", strings.Replace(testFont, `"ISO10646"`, `"ACME"`, 1), ParseOptions{Strict: true}, ErrUnknownEncoding},
+		{"negative ascent", strings.Replace(testFont, "FONT_ASCENT 6", "FONT_ASCENT -5", 1), ParseOptions{}, ErrInvalidMetrics},
+		{"short row", strings.Replace(testFont, "BBX 4 6 0 0", "BBX 12 6 0 0", 1), ParseOptions{}, ErrInvalidBitmap},
+		{"bad hex", strings.Replace(testFont, "\nE0\n", "\nZZ\n", 1), ParseOptions{}, ErrInvalidBitmap},
+		{"too large", testFont, ParseOptions{MaxBytes: 100}, ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseWithOptions([]byte(tt.src), tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// The lenient parser accepts what only Strict rejects.
+	for _, tt := range tests {
+		if !tt.opts.Strict {
+			continue
+		}
+		if _, err := Parse([]byte(tt.src)); err != nil {
+			t.Errorf("%s: non-strict Parse: %v", tt.name, err)
+		}
+	}
+}
+
+func TestParseBitsPerPixel(t *testing.T) {
+	// At two bits per pixel, latin1Font's four-pixel rows still fit a byte.
+	src := strings.Replace(latin1Font, "FONT_DESCENT 2", "FONT_DESCENT 2\nBITS_PER_PIXEL 2", 1)
+	if f := mustParse(t, src); f.BPP != 2 {
+		t.Errorf("BPP = %d, want 2", f.BPP)
+	}
+
+	src = strings.Replace(src, "SIZE 8 75 75", "SIZE 8 75 75 1", 1)
+	if f := mustParse(t, src); f.BPP != 1 {
+		t.Errorf("BPP with SIZE field = %d, want 1", f.BPP)
+	}
+}
+
+func TestParseSkipCharMap(t *testing.T) {
+	f, err := ParseWithOptions([]byte(testFont), ParseOptions{SkipCharMap: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.CharMap != nil || len(f.Characters) != 4 {
+		t.Errorf("CharMap = %v with %d characters, want nil with 4", f.CharMap, len(f.Characters))
+	}
+	if f.Characters[1].Encoding != 'A' || f.Characters[1].Alpha == nil {
+		t.Errorf("characters not decoded")
+	}
+}
+
+func TestRemap(t *testing.T) {
+	f := mustParse(t, strings.Replace(testFont, "ENCODING 66", "ENCODING 8221", 1))
+	face := f.NewFaceWithRemap(map[rune]rune{'"': '”'})
+
+	_, mask, _, _, ok := face.Glyph(fixed.P(0, 6), '"')
+	if !ok || mask != f.CharMap['”'].Alpha {
+		t.Error("Glyph('\"') did not use the '”' glyph")
+	}
+	if _, _, ok := face.GlyphBounds('"'); !ok {
+		t.Error("GlyphBounds('\"') found no glyph")
+	}
+	if a, ok := face.GlyphAdvance('"'); !ok || a != fixed.I(5) {
+		t.Errorf("GlyphAdvance('\"') = %v, %v, want 5", a, ok)
+	}
+}
+
+func TestParseAlternateEncoding(t *testing.T) {
+	src := editGlyph(testFont, "g", func(block string) string {
+		return strings.Replace(block, "ENCODING 103", "ENCODING -1 1234", 1)
+	})
+	f := mustParse(t, src)
+
+	if c := f.CharMap[1234]; c == nil || c.Name != "g" || c.RawEncoding != -1 {
+		t.Errorf("two-field ENCODING not indexed by its second field")
+	}
+	if f.HasGlyph('g') {
+		t.Errorf("glyph still indexed by its standard encoding")
+	}
+}
+
+func TestParseNoDefaultChar(t *testing.T) {
+	f := mustParse(t, strings.Replace(testFont, "DEFAULT_CHAR 32", "DEFAULT_CHAR -1", 1))
+	if f.DefaultChar != -1 {
+		t.Fatalf("DefaultChar = %d, want -1", f.DefaultChar)
+	}
+
+	face := f.NewFace()
+	if _, _, _, _, ok := face.Glyph(fixed.P(0, 6), 'Z'); ok {
+		t.Error("Glyph('Z') found a glyph")
+	}
+	if _, ok := face.GlyphAdvance('Z'); ok {
+		t.Error("GlyphAdvance('Z') found a glyph")
+	}
+	if _, ok := f.NotdefGlyph(); ok {
+		t.Error("NotdefGlyph found a glyph")
+	}
+	if got := drawString(face, "AZB"); got.Pix == nil {
+		t.Error("drawing a missing rune failed")
+	}
+}
+
+func TestParseOnlyRunes(t *testing.T) {
+	f, err := ParseWithOptions([]byte(testFont), ParseOptions{OnlyRunes: []rune(" AB")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.HasGlyph('g') || len(f.Characters) != 3 {
+		t.Errorf("HasGlyph('g') = %v with %d characters, want false with 3", f.HasGlyph('g'), len(f.Characters))
+	}
+	for _, r := range " AB" {
+		if c := f.CharMap[r]; c == nil || c.Alpha == nil {
+			t.Errorf("requested glyph %q missing", r)
+		}
+	}
+}
+
+func TestParseVerticalMetrics(t *testing.T) {
+	src := strings.Replace(testFont, "STARTPROPERTIES 6", "STARTPROPERTIES 7\nMETRICSSET 1", 1)
+	src = editGlyph(src, "A", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0", "DWIDTH1 0 -9", 1)
+	})
+	f := mustParse(t, src)
+
+	if f.MetricsSet != 1 {
+		t.Fatalf("MetricsSet = %d, want 1", f.MetricsSet)
+	}
+	if a, ok := f.NewFace().GlyphAdvance('A'); !ok || a != fixed.I(9) {
+		t.Errorf("GlyphAdvance('A') = %v, %v, want 9", a, ok)
+	}
+}
+
+func TestParseOnLine(t *testing.T) {
+	var lines []string
+	opts := ParseOptions{OnLine: func(lineNo int, raw string) {
+		if lineNo != len(lines)+1 {
+			t.Errorf("line %d reported as %d", len(lines)+1, lineNo)
+		}
+		lines = append(lines, raw)
+	}}
+	if _, err := ParseWithOptions([]byte(testFont), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := strings.Count(testFont, "\n"); len(lines) != want {
+		t.Errorf("OnLine called %d times, want %d", len(lines), want)
+	}
+	if lines[0] != "STARTFONT 2.1" || lines[len(lines)-1] != "ENDFONT" {
+		t.Errorf("first and last lines = %q, %q", lines[0], lines[len(lines)-1])
+	}
+}
+
+func TestCharacterByCode(t *testing.T) {
+	f := mustParse(t, latin1Font)
+
+	c, ok := f.CharacterByCode(233)
+	if !ok || c.Name != "eacute" || c.Encoding != 'é' {
+		t.Errorf("CharacterByCode(233) = %v, %v, want eacute", c, ok)
+	}
+	if _, ok := f.CharacterByCode(66); ok {
+		t.Error("CharacterByCode(66) found a glyph")
+	}
+	if _, ok := f.CharacterByCode(-1); ok {
+		t.Error("CharacterByCode(-1) found a glyph")
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	f, err := ParseReader(strings.NewReader("\xef\xbb\xbf" + testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Characters) != 4 {
+		t.Errorf("got %d characters, want 4", len(f.Characters))
+	}
+}
+
+func TestRoundMode(t *testing.T) {
+	f := mustParse(t, editGlyph(testFont, "B", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0", "DWIDTH 6.5 0", 1)
+	}))
+
+	for mode, want := range map[RoundMode]int{RoundNearest: 7, RoundFloor: 6, RoundCeil: 7} {
+		face := &Face{Font: f, RoundMode: mode}
+		if a, _ := face.GlyphAdvance('B'); a != fixed.I(want) {
+			t.Errorf("mode %d: GlyphAdvance('B') = %v, want %d", mode, a, want)
+		}
+		if a, _ := face.GlyphAdvance('A'); a != fixed.I(5) {
+			t.Errorf("mode %d: whole-pixel GlyphAdvance('A') = %v, want 5", mode, a)
+		}
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	f := mustParse(t, testFont)
+	want := []rune(" ABg")
+
+	if got := f.Encodings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Encodings() = %q, want %q", got, want)
+	}
+
+	var got []rune
+	f.All()(func(c *Character) bool {
+		got = append(got, c.Encoding)
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All() yielded %q, want %q", got, want)
+	}
+
+	got = nil
+	f.All()(func(c *Character) bool {
+		got = append(got, c.Encoding)
+		return len(got) < 2
+	})
+	if !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("All() after stopping yielded %q, want %q", got, want[:2])
+	}
+}
+
+func TestZeroWidthMark(t *testing.T) {
+	f := mustParse(t, marksFont)
+	face := f.NewFace()
+
+	dr, mask, _, advance, ok := face.Glyph(fixed.P(10, 6), '\u0300')
+	if !ok || !dr.Empty() || mask.Bounds().Dx() != 0 || advance != 0 {
+		t.Errorf("Glyph(U+0300) = %v, %v, %v, %v, want an empty zero-advance glyph", dr, mask.Bounds(), advance, ok)
+	}
+
+	dst := image.NewAlpha(image.Rect(0, 0, 16, 8))
+	if dr, ok := face.(*Face).GlyphInto(dst, fixed.P(10, 6), '\u0300'); !ok || !dr.Empty() {
+		t.Errorf("GlyphInto(U+0300) = %v, %v, want an empty rectangle", dr, ok)
+	}
+
+	// The mark neither draws nor moves the pen.
+	plain, marked := drawString(face, "AB"), drawString(face, "A\u0300B")
+	if !bytes.Equal(plain.Pix, marked.Pix) {
+		t.Error("drawing a zero-width mark changed the image")
+	}
+	if got, want := f.MeasureString("A\u0300B"), f.MeasureString("AB"); got != want {
+		t.Errorf("MeasureString with mark = %v, want %v", got, want)
+	}
+}
+
+func TestParseStrictRowCount(t *testing.T) {
+	short := dropLine(testFont, "E0", 0)
+
+	if _, err := Parse([]byte(short)); err != nil {
+		t.Errorf("Parse with a missing row: %v", err)
+	}
+	if _, err := ParseWithOptions([]byte(short), ParseOptions{Strict: true}); !errors.Is(err, ErrInvalidBitmap) {
+		t.Errorf("strict Parse with a missing row: error = %v, want ErrInvalidBitmap", err)
+	}
+	if _, err := ParseWithOptions([]byte(testFont), ParseOptions{Strict: true}); err != nil {
+		t.Errorf("strict Parse: %v", err)
+	}
+}
+
+func TestParseCharsets(t *testing.T) {
+	mac := strings.NewReplacer(
+		`"ISO8859"`, `"APPLE"`,
+		`"1"`, `"ROMAN"`,
+		"ENCODING 233", "ENCODING 142",
+	).Replace(latin1Font)
+	base := strings.Replace(latin1Font, `CHARSET_ENCODING "1"`, `CHARSET_ENCODING "0"`, 1)
+
+	for name, src := range map[string]string{"Mac Roman": mac, "encoding 0": base} {
+		f, err := ParseWithOptions([]byte(src), ParseOptions{Strict: true})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if c := f.CharMap['é']; c == nil || c.Name != "eacute" {
+			t.Errorf("%s: 'é' not decoded", name)
+		}
+	}
+}
+
+func TestParseWidthAfterBBX(t *testing.T) {
+	src := strings.Replace(testFont, "SWIDTH 500 0\nDWIDTH 5 0\nBBX 4 6 0 0\n", "BBX 4 6 0 0\nSWIDTH 700 0\nDWIDTH 7 0\n", 1)
+	f := mustParse(t, src)
+
+	if a := f.CharMap['A'].Advance; a != [2]int{7, 0} {
+		t.Errorf("'A' advance = %v, want [7 0]", a)
+	}
+	if lp := f.CharMap['A'].LowerPoint; lp != [2]int{0, 0} {
+		t.Errorf("'A' origin = %v, want [0 0]", lp)
+	}
+}
+
+func TestParseSkipBadGlyphs(t *testing.T) {
+	src := strings.Replace(testFont, "\nE0\n", "\nZZ\n", 1)
+	f, err := ParseWithOptions([]byte(src), ParseOptions{SkipBadGlyphs: true})
+
+	var glyphErrs GlyphErrors
+	if !errors.As(err, &glyphErrs) || len(glyphErrs) != 1 || !errors.Is(glyphErrs[0], ErrInvalidBitmap) {
+		t.Fatalf("error = %v, want one ErrInvalidBitmap", err)
+	}
+	if f.HasGlyph('B') || len(f.Characters) != 3 {
+		t.Errorf("HasGlyph('B') = %v with %d characters, want false with 3", f.HasGlyph('B'), len(f.Characters))
+	}
+	for _, r := range " Ag" {
+		if !f.HasGlyph(r) {
+			t.Errorf("good glyph %q dropped", r)
+		}
+	}
+}
+
+func TestStrokeWidth(t *testing.T) {
+	f := mustParse(t, testFont)
+	ink := func(strokeWidth int) int {
+		n := 0
+		for _, p := range drawString(&Face{Font: f, StrokeWidth: strokeWidth}, "A").Pix {
+			if p != 0 {
+				n++
+			}
+		}
+		return n
+	}
+
+	plain, bold := ink(0), ink(1)
+	if plain != 14 {
+		t.Errorf("ink without stroke = %d, want 14", plain)
+	}
+	if bold <= plain {
+		t.Errorf("ink with stroke 1 = %d, want more than %d", bold, plain)
+	}
+
+	face := &Face{Font: f, StrokeWidth: 1}
+	if a, _ := face.GlyphAdvance('A'); a != fixed.I(5) {
+		t.Errorf("stroked GlyphAdvance('A') = %v, want 5", a)
+	}
+}
+
+func TestIsUnicode(t *testing.T) {
+	if !mustParse(t, testFont).IsUnicode() {
+		t.Error("ISO10646 font: IsUnicode() = false")
+	}
+	if mustParse(t, latin1Font).IsUnicode() {
+		t.Error("ISO8859-1 font: IsUnicode() = true")
+	}
+}
+
+func TestGlyphBounds(t *testing.T) {
+	face := mustParse(t, testFont).NewFace()
+
+	// 'g' is 4x6 and hangs two pixels below the baseline.
+	bounds, advance, ok := face.GlyphBounds('g')
+	if want := fixed.R(0, -4, 4, 2); !ok || bounds != want || advance != fixed.I(5) {
+		t.Errorf("GlyphBounds('g') = %v, %v, %v, want %v, 5", bounds, advance, ok, want)
+	}
+	if bounds, _, _ := face.GlyphBounds('A'); bounds != fixed.R(0, -6, 4, 0) {
+		t.Errorf("GlyphBounds('A') = %v, want %v", bounds, fixed.R(0, -6, 4, 0))
+	}
+
+	stroked := &Face{Font: face.(*Face).Font, StrokeWidth: 1}
+	if bounds, _, _ := stroked.GlyphBounds('A'); bounds != fixed.R(-1, -7, 5, 1) {
+		t.Errorf("stroked GlyphBounds('A') = %v, want %v", bounds, fixed.R(-1, -7, 5, 1))
+	}
+}
+
+func TestParseMaxBytes(t *testing.T) {
+	opts := ParseOptions{MaxBytes: 100}
+	if _, err := ParseReaderWithOptions(strings.NewReader(testFont), opts); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("ParseReaderWithOptions error = %v, want ErrTooLarge", err)
+	}
+
+	opts.MaxBytes = int64(len(testFont))
+	if _, err := ParseReaderWithOptions(strings.NewReader(testFont), opts); err != nil {
+		t.Errorf("ParseReaderWithOptions at the limit: %v", err)
+	}
+}
+
+func TestParseFontWidth(t *testing.T) {
+	src := strings.Replace(testFont, "CHARS 4", "DWIDTH 9 0\nCHARS 4", 1)
+	src = editGlyph(src, "A", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0\n", "", 1)
+	})
+	f := mustParse(t, src)
+
+	if got := f.CharMap['A'].Advance[0]; got != 9 {
+		t.Errorf("inherited advance = %d, want 9", got)
+	}
+	if got := f.CharMap['B'].Advance[0]; got != 5 {
+		t.Errorf("own advance = %d, want 5", got)
+	}
+}
+
+func TestParseMissingEndchar(t *testing.T) {
+	last := dropLine(testFont, "ENDCHAR", 3)
+	middle := dropLine(testFont, "ENDCHAR", 1)
+
+	for name, src := range map[string]string{"last": last, "middle": middle} {
+		if _, err := Parse([]byte(src)); !errors.Is(err, ErrInvalidBitmap) {
+			t.Errorf("%s: Parse error = %v, want ErrInvalidBitmap", name, err)
+		}
+	}
+
+	f, err := ParseWithOptions([]byte(last), ParseOptions{SkipBadGlyphs: true})
+	var glyphErrs GlyphErrors
+	if !errors.As(err, &glyphErrs) || len(glyphErrs) != 1 {
+		t.Fatalf("ParseWithOptions error = %v, want one GlyphErrors entry", err)
+	}
+	if f.HasGlyph('g') || len(f.Characters) != 3 {
+		t.Errorf("unterminated glyph kept: HasGlyph('g') = %v, %d characters", f.HasGlyph('g'), len(f.Characters))
+	}
+	// The missing glyph falls back to DefaultChar instead of panicking.
+	if _, _, _, _, ok := f.NewFace().Glyph(fixed.P(0, 6), 'g'); !ok {
+		t.Error("Glyph('g') found no fallback")
+	}
+
+	f, err = ParseWithOptions([]byte(middle), ParseOptions{SkipBadGlyphs: true})
+	if err == nil || f.HasGlyph('A') || !f.HasGlyph('B') {
+		t.Errorf("middle: err = %v, HasGlyph('A') = %v, HasGlyph('B') = %v", err, f.HasGlyph('A'), f.HasGlyph('B'))
+	}
+}
+
+func TestParseGlyphCount(t *testing.T) {
+	fewer := strings.Replace(testFont, "CHARS 4", "CHARS 5", 1)
+	more := strings.Replace(testFont, "CHARS 4", "CHARS 3", 1)
+
+	f, err := Parse([]byte(fewer))
+	if err != nil {
+		t.Fatalf("Parse with fewer glyphs than CHARS: %v", err)
+	}
+	if len(f.Characters) != 4 {
+		t.Errorf("got %d characters, want 4", len(f.Characters))
+	}
+
+	if _, err := ParseWithOptions([]byte(fewer), ParseOptions{Strict: true}); !errors.Is(err, ErrGlyphCountMismatch) {
+		t.Errorf("strict Parse with fewer glyphs: error = %v, want ErrGlyphCountMismatch", err)
+	}
+	if _, err := Parse([]byte(more)); !errors.Is(err, ErrGlyphCountMismatch) {
+		t.Errorf("Parse with more glyphs: error = %v, want ErrGlyphCountMismatch", err)
+	}
+}
+
+func TestReEncodeUnknownTarget(t *testing.T) {
+	f := mustParse(t, testFont)
+	if err := f.ReEncode(charmap.Windows1252); !errors.Is(err, ErrUnknownEncoding) {
+		t.Fatalf("ReEncode(Windows1252) error = %v, want ErrUnknownEncoding", err)
+	}
+	if !f.IsUnicode() || f.CharMap['A'].RawEncoding != 'A' {
+		t.Errorf("font changed by a failed ReEncode: Encoding %q", f.Encoding)
+	}
+
+	if err := f.ReEncode(charmap.ISO8859_1); err != nil {
+		t.Fatalf("ReEncode(ISO8859_1): %v", err)
+	}
+	if f.IsUnicode() {
+		t.Errorf("IsUnicode() = true after re-encoding to %q", f.Encoding)
+	}
+}
+
+func TestDecodeWithUnmappable(t *testing.T) {
+	// 0x81 and 0x8D are unassigned in Windows-1252.
+	f := mustParse(t, strings.NewReplacer("ENCODING 66", "ENCODING 129", "ENCODING 103", "ENCODING 141").Replace(testFont))
+	if err := f.DecodeWith(charmap.Windows1252); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, ok := f.CharMap[utf8.RuneError]; ok {
+		t.Errorf("glyph %q indexed at U+FFFD", c.Name)
+	}
+	if len(f.CharMap) != 2 {
+		t.Errorf("CharMap has %d glyphs, want 2", len(f.CharMap))
+	}
+	for _, c := range f.Characters[2:] {
+		if c.Encoding != -1 || c.RawEncoding < 128 {
+			t.Errorf("glyph %q = %d, %d, want unencoded with its raw code", c.Name, c.Encoding, c.RawEncoding)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package bdf
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Outline returns one-pixel-high rectangles covering each horizontal run of
+// set pixels, top row first.
+func (c *Character) Outline() []image.Rectangle {
+	var rects []image.Rectangle
+	if c.Alpha == nil {
+		return rects
+	}
+
+	b := c.Alpha.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		start := -1
+		for x := b.Min.X; x <= b.Max.X; x++ {
+			set := x < b.Max.X && c.Alpha.AlphaAt(x, y).A > 0
+			if set && start < 0 {
+				start = x
+			} else if !set && start >= 0 {
+				rects = append(rects, image.Rect(start, y, x, y+1))
+				start = -1
+			}
+		}
+	}
+
+	return rects
+}
+
+// Rows returns each scanline of the glyph packed one bit per pixel, most
+// significant bit first and padded to a whole byte.
+func (c *Character) Rows() [][]byte {
+	if c.Alpha == nil {
+		return nil
+	}
+
+	b := c.Alpha.Rect
+	rows := make([][]byte, b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := make([]byte, (b.Dx()+7)/8)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c.Alpha.AlphaAt(x, y).A > 0 {
+				i := x - b.Min.X
+				row[i>>3] |= 0x80 >> (i % 8)
+			}
+		}
+		rows[y-b.Min.Y] = row
+	}
+
+	return rows
+}
+
+// Bits returns the glyph as rows of pixels, true where any ink is present.
+func (c *Character) Bits() [][]bool {
+	if c.Alpha == nil {
+		return nil
+	}
+
+	b := c.Alpha.Rect
+	bits := make([][]bool, b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := make([]bool, b.Dx())
+		for x := b.Min.X; x < b.Max.X; x++ {
+			row[x-b.Min.X] = c.Alpha.AlphaAt(x, y).A > 0
+		}
+		bits[y-b.Min.Y] = row
+	}
+
+	return bits
+}
+
+// NameToRune parses AGL-style glyph names such as "uni00E9" or "u1F600".
+func (c *Character) NameToRune() (rune, bool) {
+	var digits string
+	switch {
+	case strings.HasPrefix(c.Name, "uni") && len(c.Name) == 7:
+		digits = c.Name[3:]
+	case strings.HasPrefix(c.Name, "u") && len(c.Name) >= 5 && len(c.Name) <= 7:
+		digits = c.Name[1:]
+	default:
+		return 0, false
+	}
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, false
+	}
+	return rune(code), true
+}
+
+// WritePNG encodes the glyph as a grayscale PNG with inked pixels in white.
+func (c *Character) WritePNG(w io.Writer) error {
+	if c.Alpha == nil {
+		return errors.New("bdf: glyph has no bitmap")
+	}
+
+	gray := &image.Gray{
+		Pix:    append([]byte(nil), c.Alpha.Pix...),
+		Stride: c.Alpha.Stride,
+		Rect:   c.Alpha.Rect,
+	}
+	return png.Encode(w, gray)
+}
+
+// HoleCount returns the number of background regions fully enclosed by ink,
+// such as the counter of 'O'. Background is 4-connected, so ink touching
+// only diagonally still closes a hole.
+func (c *Character) HoleCount() int {
+	if c.Alpha == nil {
+		return 0
+	}
+
+	// Pad by one pixel so that all outside background forms one region.
+	b := c.Alpha.Rect
+	w, h := b.Dx()+2, b.Dy()+2
+	visited := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			inner := x > 0 && y > 0 && x < w-1 && y < h-1
+			if inner && c.Alpha.AlphaAt(b.Min.X+x-1, b.Min.Y+y-1).A > 0 {
+				visited[y*w+x] = true
+			}
+		}
+	}
+
+	regions := 0
+	var stack []int
+	for start := range visited {
+		if visited[start] {
+			continue
+		}
+
+		regions++
+		visited[start] = true
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w, i/w
+			for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[1] < 0 || n[0] >= w || n[1] >= h || visited[n[1]*w+n[0]] {
+					continue
+				}
+				visited[n[1]*w+n[0]] = true
+				stack = append(stack, n[1]*w+n[0])
+			}
+		}
+	}
+
+	// The first region found is the padded outside.
+	return regions - 1
+}
+
+// IsBlank reports whether the glyph has no set pixels.
+func (c *Character) IsBlank() bool {
+	if c.Alpha == nil {
+		return true
+	}
+	for _, v := range c.Alpha.Pix {
+		if v > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LeftSideBearing returns the distance from the origin to the left edge of
+// the bitmap.
+func (c *Character) LeftSideBearing() int {
+	return c.LowerPoint[0]
+}
+
+// RightSideBearing returns the distance from the right edge of the bitmap to
+// the advance. It is negative when the bitmap extends past the advance.
+func (c *Character) RightSideBearing() int {
+	width := 0
+	if c.Alpha != nil {
+		width = c.Alpha.Rect.Dx()
+	}
+	return c.Advance[0] - c.LowerPoint[0] - width
+}
+
+// Invert replaces each alpha value with 255 minus itself, in place. This
+// inverts coverage rather than colour: the glyph's background within its
+// bounding box becomes ink and its ink becomes transparent.
+func (c *Character) Invert() {
+	if c.Alpha == nil {
+		return
+	}
+	for i, v := range c.Alpha.Pix {
+		c.Alpha.Pix[i] = 255 - v
+	}
+}
+
+// Equal reports whether c and o have the same encoding, advance, origin
+// and pixels. Glyph names are not compared. A nil Character equals only nil.
+func (c *Character) Equal(o *Character) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	if c.Encoding != o.Encoding || c.Advance != o.Advance || c.LowerPoint != o.LowerPoint {
+		return false
+	}
+	if c.Alpha == nil || o.Alpha == nil {
+		return c.Alpha == o.Alpha
+	}
+
+	b := c.Alpha.Rect
+	if b.Size() != o.Alpha.Rect.Size() {
+		return false
+	}
+	d := o.Alpha.Rect.Min.Sub(b.Min)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c.Alpha.AlphaAt(x, y) != o.Alpha.AlphaAt(x+d.X, y+d.Y) {
+				return false
+			}
+		}
+	}
+	return true
+}
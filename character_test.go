@@ -0,0 +1,194 @@
+package bdf
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestOutline(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	// One rectangle per horizontal run of ink.
+	rects := f.CharMap['A'].Outline()
+	if len(rects) != 10 {
+		t.Errorf("'A' outline has %d rectangles, want 10", len(rects))
+	}
+	if rects[0] != image.Rect(1, 0, 3, 1) {
+		t.Errorf("first rectangle = %v, want (1,0)-(3,1)", rects[0])
+	}
+	if n := len(f.CharMap[' '].Outline()); n != 0 {
+		t.Errorf("space outline has %d rectangles, want 0", n)
+	}
+}
+
+// glyph builds a character from rows of '#' (ink) and '.' (background).
+func glyph(rows ...string) *Character {
+	w := 0
+	if len(rows) > 0 {
+		w = len(rows[0])
+	}
+	a := image.NewAlpha(image.Rect(0, 0, w, len(rows)))
+	for y, row := range rows {
+		for x, p := range row {
+			if p == '#' {
+				a.Pix[y*a.Stride+x] = 0xff
+			}
+		}
+	}
+	return &Character{Alpha: a, Advance: [2]int{w + 1, 0}}
+}
+
+func TestRows(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	want := [][]byte{{0x70}, {0x90}, {0x90}, {0x70}, {0x10}, {0x60}}
+	if got := f.CharMap['g'].Rows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("'g' rows = %x, want %x", got, want)
+	}
+
+	wide := glyph("#........#")
+	if got := wide.Rows(); !reflect.DeepEqual(got, [][]byte{{0x80, 0x40}}) {
+		t.Errorf("10-pixel row = %x, want [80 40]", got)
+	}
+}
+
+func TestNameToRune(t *testing.T) {
+	for name, want := range map[string]rune{"uni00E9": 'é', "u1F600": '😀', "uni0041": 'A'} {
+		if r, ok := (&Character{Name: name}).NameToRune(); !ok || r != want {
+			t.Errorf("NameToRune(%q) = %q, %v, want %q", name, r, ok, want)
+		}
+	}
+	for _, name := range []string{"A", "eacute", "uniZZZZ", "u110000", "uni00E"} {
+		if r, ok := (&Character{Name: name}).NameToRune(); ok {
+			t.Errorf("NameToRune(%q) = %q, want no rune", name, r)
+		}
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	var buf bytes.Buffer
+	if err := f.CharMap['g'].WritePNG(&buf); err != nil {
+		t.Fatalf("WritePNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(4, 6) {
+		t.Errorf("PNG size = %v, want 4x6", got)
+	}
+}
+
+func TestHoleCount(t *testing.T) {
+	o := glyph(
+		".##.",
+		"#..#",
+		"#..#",
+		".##.",
+	)
+	b := glyph(
+		"###.",
+		"#..#",
+		"###.",
+		"#..#",
+		"###.",
+	)
+	i := glyph(
+		"###",
+		".#.",
+		"###",
+	)
+	for name, tc := range map[string]struct {
+		c    *Character
+		want int
+	}{"O": {o, 1}, "B": {b, 2}, "I": {i, 0}} {
+		if got := tc.c.HoleCount(); got != tc.want {
+			t.Errorf("%s.HoleCount() = %d, want %d", name, got, tc.want)
+		}
+	}
+}
+
+func TestIsBlank(t *testing.T) {
+	f := mustParse(t, testFont)
+	if !f.CharMap[' '].IsBlank() {
+		t.Error("space is not blank")
+	}
+	if f.CharMap['A'].IsBlank() {
+		t.Error("'A' is blank")
+	}
+}
+
+func TestSideBearings(t *testing.T) {
+	c := glyph("##", "##")
+	c.LowerPoint = [2]int{1, 0}
+	c.Advance = [2]int{6, 0}
+
+	if got := c.LeftSideBearing(); got != 1 {
+		t.Errorf("LeftSideBearing() = %d, want 1", got)
+	}
+	if got := c.RightSideBearing(); got != 3 {
+		t.Errorf("RightSideBearing() = %d, want 3", got)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	c := glyph("#.")
+	c.Invert()
+	if got := c.Alpha.Pix; got[0] != 0 || got[1] != 0xff {
+		t.Errorf("inverted pixels = %v, want [0 255]", got)
+	}
+
+	f := mustParse(t, testFont)
+	f.InvertAll()
+	if got := f.CharMap['A'].Alpha.Pix; got[0] != 0xff || got[1] != 0 {
+		t.Error("InvertAll did not invert 'A'")
+	}
+}
+
+func TestBits(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	want := [][]bool{
+		{false, true, true, false},
+		{true, false, false, true},
+		{true, false, false, true},
+		{true, true, true, true},
+		{true, false, false, true},
+		{true, false, false, true},
+	}
+	if got := f.CharMap['A'].Bits(); !reflect.DeepEqual(got, want) {
+		t.Errorf("'A' bits = %v, want %v", got, want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := glyph("#.", ".#")
+	b := glyph("#.", ".#")
+	if !a.Equal(b) {
+		t.Error("identical glyphs are not equal")
+	}
+
+	b.Alpha.Pix[1] = 0xff
+	if a.Equal(b) {
+		t.Error("glyphs one pixel apart are equal")
+	}
+
+	c := glyph("#.", ".#")
+	c.LowerPoint[1] = -1
+	if a.Equal(c) {
+		t.Error("glyphs with different origins are equal")
+	}
+
+	var none *Character
+	if a.Equal(nil) || none.Equal(a) {
+		t.Error("a glyph equals nil")
+	}
+	if !none.Equal(nil) {
+		t.Error("nil does not equal nil")
+	}
+}
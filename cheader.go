@@ -0,0 +1,62 @@
+package bdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// WriteCHeader writes the font as C source for embedding in firmware. Glyph
+// bitmaps are packed one bit per pixel, row by row, each row padded to a
+// whole byte, and indexed by a table sorted by code point.
+func (f *Font) WriteCHeader(w io.Writer, varName string) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "/* %s */\n\n", f.Name)
+	fmt.Fprintf(&buf, "#include <stdint.h>\n\n")
+
+	runes := f.sortedRunes()
+	offsets := make([]int, len(runes))
+
+	fmt.Fprintf(&buf, "static const uint8_t %s_bitmaps[] = {\n", varName)
+	offset := 0
+	for i, r := range runes {
+		offsets[i] = offset
+		fmt.Fprintf(&buf, "\t/* U+%04X */\n", r)
+		for _, row := range f.CharMap[r].Rows() {
+			if len(row) == 0 {
+				continue
+			}
+			buf.WriteString("\t")
+			for _, b := range row {
+				fmt.Fprintf(&buf, "0x%02X, ", b)
+			}
+			buf.Truncate(buf.Len() - 1)
+			buf.WriteString("\n")
+			offset += len(row)
+		}
+	}
+	fmt.Fprintf(&buf, "};\n\n")
+
+	fmt.Fprintf(&buf, "static const struct {\n")
+	fmt.Fprintf(&buf, "\tuint32_t codepoint;\n")
+	fmt.Fprintf(&buf, "\tuint32_t offset;\n")
+	fmt.Fprintf(&buf, "\tuint8_t width;\n")
+	fmt.Fprintf(&buf, "\tuint8_t height;\n")
+	fmt.Fprintf(&buf, "\tint8_t x_offset;\n")
+	fmt.Fprintf(&buf, "\tint8_t y_offset;\n")
+	fmt.Fprintf(&buf, "\tint8_t advance;\n")
+	fmt.Fprintf(&buf, "} %s_glyphs[] = {\n", varName)
+	for i, r := range runes {
+		c := f.CharMap[r]
+		var width, height int
+		if c.Alpha != nil {
+			width, height = c.Alpha.Rect.Dx(), c.Alpha.Rect.Dy()
+		}
+		fmt.Fprintf(&buf, "\t{ 0x%04X, %d, %d, %d, %d, %d, %d },\n", r, offsets[i], width, height, c.LowerPoint[0], c.LowerPoint[1], c.Advance[0])
+	}
+	fmt.Fprintf(&buf, "};\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
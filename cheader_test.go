@@ -0,0 +1,14 @@
+package bdf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := mustParse(t, testFont).WriteCHeader(&buf, "test_font"); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "cheader.golden", buf.Bytes())
+}
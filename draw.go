@@ -0,0 +1,21 @@
+package bdf
+
+import (
+	"image"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Draw draws s onto dst with its baseline starting at dot, using src as the
+// colour source.
+func (f *Font) Draw(dst draw.Image, dot image.Point, s string, src image.Image) {
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  src,
+		Face: f.NewFace(),
+		Dot:  fixed.P(dot.X, dot.Y),
+	}
+	d.DrawString(s)
+}
@@ -0,0 +1,30 @@
+package bdf
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDraw(t *testing.T) {
+	f := mustParse(t, testFont)
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	red := image.NewUniform(color.RGBA{R: 0xff, A: 0xff})
+
+	f.Draw(dst, image.Pt(1, 6), "AB", red)
+
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{2, 0, color.RGBA{R: 0xff, A: 0xff}}, // top of 'A'
+		{1, 0, color.RGBA{}},                 // left of the apex
+		{6, 0, color.RGBA{R: 0xff, A: 0xff}}, // top-left of 'B'
+		{6, 6, color.RGBA{}},                 // below the baseline
+	}
+	for _, tt := range tests {
+		if got := dst.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
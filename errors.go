@@ -0,0 +1,28 @@
+package bdf
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidBBX            = errors.New("bdf: invalid BBX")
+	ErrGlyphCountMismatch    = errors.New("bdf: glyph count does not match CHARS")
+	ErrPropertyCountMismatch = errors.New("bdf: property count does not match STARTPROPERTIES")
+	ErrUnknownEncoding       = errors.New("bdf: unknown encoding")
+	ErrInvalidMetrics        = errors.New("bdf: invalid font metrics")
+	ErrInvalidBitmap         = errors.New("bdf: invalid glyph bitmap")
+	ErrTooLarge              = errors.New("bdf: font data too large")
+)
+
+// GlyphErrors collects the glyphs dropped when parsing with
+// ParseOptions.SkipBadGlyphs.
+type GlyphErrors []error
+
+func (e GlyphErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,50 @@
+package bdf
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontSet is a font.Face that looks up each rune in its fonts in order,
+// falling back to the first font's DefaultChar when none has the rune.
+// Metrics come from the first font.
+type FontSet []*Font
+
+func (s FontSet) face(r rune) *Face {
+	for _, f := range s {
+		if f.HasGlyph(r) {
+			return &Face{Font: f}
+		}
+	}
+	if len(s) == 0 {
+		return &Face{Font: &Font{}}
+	}
+	return &Face{Font: s[0]}
+}
+
+func (s FontSet) Close() error { return nil }
+
+func (s FontSet) Metrics() font.Metrics {
+	if len(s) == 0 {
+		return font.Metrics{}
+	}
+	return (&Face{Font: s[0]}).Metrics()
+}
+
+func (s FontSet) Kern(_, _ rune) fixed.Int26_6 {
+	return 0
+}
+
+func (s FontSet) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return s.face(r).Glyph(dot, r)
+}
+
+func (s FontSet) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	return s.face(r).GlyphBounds(r)
+}
+
+func (s FontSet) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	return s.face(r).GlyphAdvance(r)
+}
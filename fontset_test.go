@@ -0,0 +1,40 @@
+package bdf
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestFontSet(t *testing.T) {
+	latin := mustParse(t, testFont)
+	// The fallback has a wider arrow where testFont has 'B'.
+	wide := editGlyph(testFont, "B", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0", "DWIDTH 7 0", 1)
+	})
+	symbols := mustParse(t, strings.NewReplacer(
+		"ENCODING 66", "ENCODING 8594",
+		"FONT_ASCENT 6", "FONT_ASCENT 7",
+	).Replace(wide))
+	set := FontSet{latin, symbols}
+
+	if got := set.Metrics().Ascent; got != fixed.I(6) {
+		t.Errorf("Metrics().Ascent = %v, want the primary's 6", got)
+	}
+
+	_, mask, _, advance, ok := set.Glyph(fixed.P(0, 6), '→')
+	if !ok || mask != symbols.CharMap['→'].Alpha || advance != fixed.I(7) {
+		t.Errorf("Glyph('→') did not come from the fallback")
+	}
+	if _, mask, _, _, _ := set.Glyph(fixed.P(0, 6), 'A'); mask != latin.CharMap['A'].Alpha {
+		t.Errorf("Glyph('A') did not come from the primary")
+	}
+	if _, ok := set.GlyphAdvance('Z'); !ok {
+		t.Errorf("GlyphAdvance('Z') did not fall back to the primary's DefaultChar")
+	}
+
+	if _, ok := (FontSet{}).GlyphAdvance('A'); ok {
+		t.Errorf("empty FontSet found a glyph")
+	}
+}
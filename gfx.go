@@ -0,0 +1,101 @@
+package bdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// WriteGFXFont writes the font as an Adafruit GFX font: a GFXglyph table
+// covering every code point from the lowest to the highest in the font, a
+// bitmap blob packed one bit per pixel with no row padding, and a GFXfont
+// named varName. Only 1-bit fonts with code points up to U+FFFF are
+// supported.
+func (f *Font) WriteGFXFont(w io.Writer, varName string) error {
+	if f.BPP != 1 {
+		return fmt.Errorf("bdf: GFX export supports 1-bit fonts only, font is %d-bit", f.BPP)
+	}
+
+	runes := f.sortedRunes()
+	if len(runes) == 0 {
+		return fmt.Errorf("bdf: GFX export needs at least one glyph")
+	}
+	first, last := runes[0], runes[len(runes)-1]
+	if first < 0 || last > 0xFFFF {
+		return fmt.Errorf("bdf: GFX export supports code points U+0000 to U+FFFF, font has U+%04X to U+%04X", first, last)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "/* %s */\n\n", f.Name)
+	fmt.Fprintf(&buf, "#include <Adafruit_GFX.h>\n\n")
+
+	offsets := make(map[rune]int, len(runes))
+	fmt.Fprintf(&buf, "const uint8_t %sBitmaps[] PROGMEM = {\n", varName)
+	offset := 0
+	for _, r := range runes {
+		offsets[r] = offset
+		packed := gfxBits(f.CharMap[r])
+		if len(packed) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\t/* U+%04X */ ", r)
+		for _, b := range packed {
+			fmt.Fprintf(&buf, "0x%02X, ", b)
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteString("\n")
+		offset += len(packed)
+	}
+	if offset > 0xFFFF {
+		return fmt.Errorf("bdf: GFX bitmap data is %d bytes, more than 65535", offset)
+	}
+	fmt.Fprintf(&buf, "};\n\n")
+
+	fmt.Fprintf(&buf, "const GFXglyph %sGlyphs[] PROGMEM = {\n", varName)
+	for r := first; r <= last; r++ {
+		c, ok := f.CharMap[r]
+		if !ok {
+			fmt.Fprintf(&buf, "\t{ 0, 0, 0, 0, 0, 0 }, /* U+%04X */\n", r)
+			continue
+		}
+		var width, height int
+		if c.Alpha != nil {
+			width, height = c.Alpha.Rect.Dx(), c.Alpha.Rect.Dy()
+		}
+		// GFX offsets are from the cursor on the baseline to the top left.
+		fmt.Fprintf(&buf, "\t{ %d, %d, %d, %d, %d, %d }, /* U+%04X */\n",
+			offsets[r], width, height, c.Advance[0], c.LowerPoint[0], -(c.LowerPoint[1] + height), r)
+	}
+	fmt.Fprintf(&buf, "};\n\n")
+
+	fmt.Fprintf(&buf, "const GFXfont %s PROGMEM = {\n", varName)
+	fmt.Fprintf(&buf, "\t(uint8_t *)%sBitmaps,\n", varName)
+	fmt.Fprintf(&buf, "\t(GFXglyph *)%sGlyphs,\n", varName)
+	fmt.Fprintf(&buf, "\t0x%04X, 0x%04X, %d\n", first, last, f.Ascent+f.Descent)
+	fmt.Fprintf(&buf, "};\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// gfxBits packs c's pixels one bit per pixel, most significant bit first,
+// running on from one row to the next.
+func gfxBits(c *Character) []byte {
+	if c.Alpha == nil {
+		return nil
+	}
+
+	b := c.Alpha.Rect
+	packed := make([]byte, (b.Dx()*b.Dy()+7)/8)
+	i := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c.Alpha.AlphaAt(x, y).A > 0 {
+				packed[i>>3] |= 0x80 >> (i % 8)
+			}
+			i++
+		}
+	}
+	return packed
+}
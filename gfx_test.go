@@ -0,0 +1,14 @@
+package bdf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteGFXFont(t *testing.T) {
+	var buf bytes.Buffer
+	if err := mustParse(t, testFont).WriteGFXFont(&buf, "TestFont"); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "gfx.golden", buf.Bytes())
+}
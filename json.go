@@ -0,0 +1,77 @@
+package bdf
+
+import (
+	"encoding/json"
+)
+
+type jsonFont struct {
+	Name        string          `json:"name"`
+	Size        int             `json:"size"`
+	PixelSize   int             `json:"pixelSize"`
+	DPI         [2]int          `json:"dpi"`
+	BPP         int             `json:"bpp"`
+	Ascent      int             `json:"ascent"`
+	Descent     int             `json:"descent"`
+	CapHeight   int             `json:"capHeight"`
+	XHeight     int             `json:"xHeight"`
+	Encoding    string          `json:"encoding"`
+	DefaultChar rune            `json:"defaultChar"`
+	Characters  []jsonCharacter `json:"characters"`
+}
+
+type jsonCharacter struct {
+	Name     string `json:"name"`
+	Encoding rune   `json:"encoding"`
+	Advance  [2]int `json:"advance"`
+	BBX      [4]int `json:"bbx"`
+	Alpha    []byte `json:"alpha,omitempty"`
+}
+
+// MarshalJSON encodes the font's metadata and each glyph's metrics, without
+// pixel data.
+func (f *Font) MarshalJSON() ([]byte, error) {
+	return f.marshalJSON(false)
+}
+
+// MarshalJSONWithPixels is like MarshalJSON but also includes each glyph's
+// alpha values, base64 encoded.
+func (f *Font) MarshalJSONWithPixels() ([]byte, error) {
+	return f.marshalJSON(true)
+}
+
+func (f *Font) marshalJSON(includePixels bool) ([]byte, error) {
+	jf := jsonFont{
+		Name:        f.Name,
+		Size:        f.Size,
+		PixelSize:   f.PixelSize,
+		DPI:         f.DPI,
+		BPP:         f.BPP,
+		Ascent:      f.Ascent,
+		Descent:     f.Descent,
+		CapHeight:   f.CapHeight,
+		XHeight:     f.XHeight,
+		Encoding:    f.Encoding,
+		DefaultChar: f.DefaultChar,
+		Characters:  make([]jsonCharacter, len(f.Characters)),
+	}
+
+	for i, c := range f.Characters {
+		jc := jsonCharacter{
+			Name:     c.Name,
+			Encoding: c.Encoding,
+			Advance:  c.Advance,
+		}
+		jc.BBX[2] = c.LowerPoint[0]
+		jc.BBX[3] = c.LowerPoint[1]
+		if c.Alpha != nil {
+			jc.BBX[0] = c.Alpha.Rect.Dx()
+			jc.BBX[1] = c.Alpha.Rect.Dy()
+			if includePixels {
+				jc.Alpha = c.Alpha.Pix
+			}
+		}
+		jf.Characters[i] = jc
+	}
+
+	return json.Marshal(jf)
+}
@@ -0,0 +1,51 @@
+package bdf
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got jsonFont
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != f.Name || got.Size != 8 || got.PixelSize != 8 || got.DPI != [2]int{75, 75} ||
+		got.BPP != 1 || got.Ascent != 6 || got.Descent != 2 || got.Encoding != f.Encoding || got.DefaultChar != ' ' {
+		t.Errorf("font metadata = %+v", got)
+	}
+	if len(got.Characters) != 4 {
+		t.Fatalf("got %d characters, want 4", len(got.Characters))
+	}
+	want := jsonCharacter{Name: "g", Encoding: 'g', Advance: [2]int{5, 0}, BBX: [4]int{4, 6, 0, -2}}
+	if !reflect.DeepEqual(got.Characters[3], want) {
+		t.Errorf("'g' = %+v, want %+v", got.Characters[3], want)
+	}
+}
+
+func TestMarshalJSONWithPixels(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	data, err := f.MarshalJSONWithPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got jsonFont
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, c := range got.Characters {
+		if !reflect.DeepEqual(c.Alpha, f.Characters[i].Alpha.Pix) {
+			t.Errorf("glyph %q pixels differ", c.Name)
+		}
+	}
+}
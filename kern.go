@@ -0,0 +1,77 @@
+package bdf
+
+// ImportKerningFromProperties adds the kerning pairs that parse extracts from
+// the font's properties to Kerning, replacing existing values for the same
+// pairs. It lets callers support whatever convention their fonts use.
+func (f *Font) ImportKerningFromProperties(parse func(properties map[string]string) map[[2]rune]int) {
+	pairs := parse(f.Properties)
+	if len(pairs) == 0 {
+		return
+	}
+
+	if f.Kerning == nil {
+		f.Kerning = make(map[[2]rune]int, len(pairs))
+	}
+	for pair, k := range pairs {
+		f.Kerning[pair] = k
+	}
+	f.InvalidateCache()
+}
+
+// autoKern approximates kerning between a and b from their ink profiles,
+// pulling b towards a until the closest rows would be one pixel apart. The
+// adjustment never widens the pair and never exceeds half of a's advance.
+func (f *Font) autoKern(a, b rune) int {
+	ca, cb := f.lookup(a), f.lookup(b)
+	if ca == nil || cb == nil || ca.Alpha == nil || cb.Alpha == nil {
+		return 0
+	}
+
+	right := inkProfile(ca, true)
+	left := inkProfile(cb, false)
+
+	gap := -1
+	for y, r := range right {
+		l, ok := left[y]
+		if !ok {
+			continue
+		}
+		if g := (ca.Advance[0] - r) + l; gap < 0 || g < gap {
+			gap = g
+		}
+	}
+	if gap <= 1 {
+		return 0
+	}
+
+	kern := gap - 1
+	if limit := ca.Advance[0] / 2; kern > limit {
+		kern = limit
+	}
+	return -kern
+}
+
+// inkProfile maps each inked row, measured upwards from the baseline, to the
+// pen-relative x of its leftmost inked pixel or, if right is set, to the x
+// just past its rightmost inked pixel.
+func inkProfile(c *Character, right bool) map[int]int {
+	profile := make(map[int]int)
+
+	b := c.Alpha.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		by := c.LowerPoint[1] + b.Max.Y - 1 - y
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c.Alpha.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			px := c.LowerPoint[0] + x - b.Min.X
+			if right {
+				profile[by] = px + 1
+			} else if _, ok := profile[by]; !ok {
+				profile[by] = px
+			}
+		}
+	}
+
+	return profile
+}
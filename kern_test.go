@@ -0,0 +1,91 @@
+package bdf
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// avFont has an 'A' and a 'V' whose diagonals leave room to kern.
+const avFont = `STARTFONT 2.1
+FONT av
+SIZE 8 75 75
+FONT_ASCENT 3
+FONT_DESCENT 0
+CHARS 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 6 0
+BBX 5 3 0 0
+BITMAP
+20
+50
+88
+ENDCHAR
+STARTCHAR V
+ENCODING 86
+DWIDTH 6 0
+BBX 5 3 0 0
+BITMAP
+88
+50
+20
+ENDCHAR
+ENDFONT
+`
+
+func TestAutoKern(t *testing.T) {
+	f := mustParse(t, avFont)
+	plain := &Face{Font: f}
+	kerned := &Face{Font: f, AutoKern: true}
+
+	if k := plain.Kern('A', 'V'); k != 0 {
+		t.Errorf("Kern('A', 'V') without AutoKern = %v, want 0", k)
+	}
+	// Every row of "AV" is three pixels apart; kerning leaves one.
+	if k := kerned.Kern('A', 'V'); k != fixed.I(-2) {
+		t.Errorf("Kern('A', 'V') = %v, want -2", k)
+	}
+	if k := kerned.Kern('A', 'A'); k != 0 {
+		t.Errorf("Kern('A', 'A') = %v, want 0", k)
+	}
+
+	if a, b := font.MeasureString(kerned, "AV"), font.MeasureString(plain, "AV"); a >= b {
+		t.Errorf("kerned \"AV\" is %v wide, want less than %v", a, b)
+	}
+}
+
+func TestImportKerningFromProperties(t *testing.T) {
+	src := strings.Replace(avFont, "CHARS 2", "STARTPROPERTIES 1\nKERN_PAIRS \"A V -1;V A -3\"\nENDPROPERTIES\nCHARS 2", 1)
+	f := mustParse(t, src)
+
+	f.ImportKerningFromProperties(func(properties map[string]string) map[[2]rune]int {
+		pairs := make(map[[2]rune]int)
+		for _, pair := range strings.Split(properties["KERN_PAIRS"], ";") {
+			fields := strings.Fields(pair)
+			k, err := strconv.Atoi(fields[2])
+			if err != nil {
+				t.Fatal(err)
+			}
+			pairs[[2]rune{rune(fields[0][0]), rune(fields[1][0])}] = k
+		}
+		return pairs
+	})
+
+	face := f.NewFace()
+	if k := face.Kern('A', 'V'); k != fixed.I(-1) {
+		t.Errorf("Kern('A', 'V') = %v, want -1", k)
+	}
+	if k := face.Kern('V', 'A'); k != fixed.I(-3) {
+		t.Errorf("Kern('V', 'A') = %v, want -3", k)
+	}
+
+	empty := mustParse(t, avFont)
+	empty.ImportKerningFromProperties(func(map[string]string) map[[2]rune]int { return nil })
+	if empty.Kerning != nil {
+		t.Errorf("Kerning = %v after importing nothing, want nil", empty.Kerning)
+	}
+}
@@ -0,0 +1,72 @@
+package bdf
+
+import (
+	"image"
+)
+
+// Merge returns a new font containing the glyphs of f followed by any glyphs
+// of others whose runes are not already present. Fonts with differing bit
+// depths are promoted to the deepest one.
+func (f *Font) Merge(others ...*Font) *Font {
+	m := f.cloneMetadata()
+	m.CharMap = make(map[rune]*Character)
+
+	fonts := append([]*Font{f}, others...)
+	for _, o := range fonts {
+		if o.BPP > m.BPP {
+			m.BPP = o.BPP
+		}
+		if o.Ascent > m.Ascent {
+			m.Ascent = o.Ascent
+		}
+		if o.Descent > m.Descent {
+			m.Descent = o.Descent
+		}
+	}
+
+	seen := make(map[rune]bool)
+	for _, o := range fonts {
+		for _, c := range o.Characters {
+			if _, ok := o.CharMap[c.Encoding]; ok {
+				if seen[c.Encoding] {
+					continue
+				}
+				seen[c.Encoding] = true
+			}
+
+			if c.Alpha != nil {
+				c.Alpha = requantize(c.Alpha, m.BPP)
+			}
+			m.Characters = append(m.Characters, c)
+		}
+	}
+
+	for i := range m.Characters {
+		c := &m.Characters[i]
+		if seen[c.Encoding] {
+			if _, ok := m.CharMap[c.Encoding]; !ok {
+				m.CharMap[c.Encoding] = c
+			}
+		}
+	}
+
+	m.buildASCII()
+
+	return m
+}
+
+func requantize(a *image.Alpha, bpp int) *image.Alpha {
+	levels := (1 << bpp) - 1
+
+	out := &image.Alpha{
+		Stride: a.Stride,
+		Rect:   a.Rect,
+		Pix:    make([]byte, len(a.Pix)),
+	}
+	for i, v := range a.Pix {
+		level := (int(v)*levels + 127) / 255
+		out.Pix[i] = byte(level * 255 / levels)
+	}
+
+	return out
+}
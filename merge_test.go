@@ -0,0 +1,81 @@
+package bdf
+
+import (
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// grayFont is a 4-bit font with one two-pixel glyph, fully and about half
+// inked.
+const grayFont = `STARTFONT 2.1
+FONT gray
+SIZE 8 75 75 4
+FONT_ASCENT 6
+FONT_DESCENT 2
+CHARSET_REGISTRY "ISO10646"
+CHARSET_ENCODING "1"
+CHARS 2
+STARTCHAR C
+ENCODING 67
+DWIDTH 3 0
+BBX 2 1 0 0
+BITMAP
+F8
+ENDCHAR
+STARTCHAR A
+ENCODING 65
+DWIDTH 9 0
+BBX 1 1 0 0
+BITMAP
+F0
+ENDCHAR
+ENDFONT
+`
+
+func TestMergeBPP(t *testing.T) {
+	f := mustParse(t, testFont)
+	gray := mustParse(t, grayFont)
+
+	m := f.Merge(gray)
+	if m.BPP != 4 {
+		t.Errorf("BPP = %d, want 4", m.BPP)
+	}
+	if len(m.CharMap) != 5 {
+		t.Errorf("merged %d runes, want 5", len(m.CharMap))
+	}
+
+	// The first font wins for runes both fonts have.
+	if a := m.CharMap['A'].Advance[0]; a != 5 {
+		t.Errorf("'A' advance = %d, want 5 from the first font", a)
+	}
+	if got := m.CharMap['A'].Alpha.Pix[1]; got != 0xff {
+		t.Errorf("requantized 'A' pixel = %#x, want 0xff", got)
+	}
+	if got := m.CharMap['C'].Alpha.Pix; got[0] != 0xff || got[1] != 8*0xff/15 {
+		t.Errorf("'C' pixels = %v, want [255 136]", got)
+	}
+}
+
+func TestMergeDoesNotShareState(t *testing.T) {
+	f := mustParse(t, testFont)
+	f.Kerning = map[[2]rune]int{{'A', 'B'}: -1}
+
+	m := f.Merge()
+	m.OverrideAdvance('A', 2)
+	m.Kerning[[2]rune{'A', 'B'}] = -3
+	m.Properties["FONT_ASCENT"] = "9"
+
+	if len(f.advanceOverrides) != 0 {
+		t.Errorf("source overrides = %v, want none", f.advanceOverrides)
+	}
+	if a, _ := f.NewFace().GlyphAdvance('A'); a != fixed.I(5) {
+		t.Errorf("source GlyphAdvance('A') = %v, want 5", a)
+	}
+	if k := f.Kerning[[2]rune{'A', 'B'}]; k != -1 {
+		t.Errorf("source kerning = %d, want -1", k)
+	}
+	if p := f.Properties["FONT_ASCENT"]; p != "6" {
+		t.Errorf("source FONT_ASCENT property = %q, want 6", p)
+	}
+}
@@ -0,0 +1,133 @@
+package bdf
+
+import (
+	"image"
+)
+
+// ComputeCapXHeight fills in CapHeight and XHeight, when they are zero, from
+// the ink tops of the 'H' and 'x' glyphs.
+func (f *Font) ComputeCapXHeight() {
+	if f.CapHeight == 0 {
+		if h, ok := f.inkTop('H'); ok {
+			f.CapHeight = h
+		}
+	}
+
+	if f.XHeight == 0 {
+		if h, ok := f.inkTop('x'); ok {
+			f.XHeight = h
+		}
+	}
+}
+
+func (f *Font) inkTop(r rune) (int, bool) {
+	c, ok := f.CharMap[r]
+	if !ok || c.Alpha == nil {
+		return 0, false
+	}
+
+	b := c.Alpha.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c.Alpha.AlphaAt(x, y).A > 0 {
+				return c.LowerPoint[1] + b.Max.Y - y, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// GlyphAdvancePixels returns the advance of r rounded to whole pixels.
+func (f *Font) GlyphAdvancePixels(r rune) (int, bool) {
+	a, ok := f.advance(r)
+	return a.Round(), ok
+}
+
+// GlyphBoundsPixels returns the BBX of the glyph for r relative to its origin,
+// in pixels with y increasing downwards.
+func (f *Font) GlyphBoundsPixels(r rune) (image.Rectangle, bool) {
+	c := f.lookup(r)
+	if c == nil {
+		return image.Rect(0, -f.Ascent, 0, f.Descent), false
+	}
+	x, y := c.LowerPoint[0], -c.LowerPoint[1]
+	return image.Rect(x, y-c.Alpha.Rect.Dy(), x+c.Alpha.Rect.Dx(), y), true
+}
+
+// MaxGlyphSize returns the largest bitmap width and height over all glyphs.
+// The two need not come from the same glyph.
+func (f *Font) MaxGlyphSize() (w, h int) {
+	for _, c := range f.Characters {
+		if c.Alpha == nil {
+			continue
+		}
+		if dx := c.Alpha.Rect.Dx(); dx > w {
+			w = dx
+		}
+		if dy := c.Alpha.Rect.Dy(); dy > h {
+			h = dy
+		}
+	}
+	return w, h
+}
+
+func (f *Font) IsMonospace() bool {
+	return len(f.Characters) > 0 && len(f.AdvanceHistogram()) == 1
+}
+
+// AdvanceHistogram counts the glyphs with each horizontal advance. A
+// monospaced font has a single entry.
+func (f *Font) AdvanceHistogram() map[int]int {
+	hist := make(map[int]int)
+	for _, c := range f.Characters {
+		hist[c.Advance[0]]++
+	}
+	return hist
+}
+
+// EmMetrics returns Ascent and Descent as fractions of the em, taken to be
+// PixelSize. Both are zero if the font has no pixel size.
+func (f *Font) EmMetrics() (ascentEm, descentEm float64) {
+	if f.PixelSize <= 0 {
+		return 0, 0
+	}
+	em := float64(f.PixelSize)
+	return float64(f.Ascent) / em, float64(f.Descent) / em
+}
+
+// CoverageBitmap returns a bitset of the runes in CharMap: rune r is present
+// when bit r%64 of word r/64 is set. The slice ends at the word holding the
+// highest rune.
+func (f *Font) CoverageBitmap() []uint64 {
+	var bits []uint64
+	for r := range f.CharMap {
+		if r < 0 {
+			continue
+		}
+		word := int(r / 64)
+		for len(bits) <= word {
+			bits = append(bits, 0)
+		}
+		bits[word] |= 1 << uint(r%64)
+	}
+	return bits
+}
+
+// LineHeight returns the distance between baselines of consecutive lines:
+// the value given to SetLineHeight, or else Ascent plus Descent.
+func (f *Font) LineHeight() int {
+	if f.lineHeight > 0 {
+		return f.lineHeight
+	}
+	return f.Ascent + f.Descent
+}
+
+// SetLineHeight overrides the line height reported by LineHeight and used
+// by faces of the font. A value of zero or less restores the default.
+func (f *Font) SetLineHeight(px int) {
+	if px < 0 {
+		px = 0
+	}
+	f.lineHeight = px
+}
@@ -0,0 +1,160 @@
+package bdf
+
+import (
+	"image"
+	"testing"
+)
+
+// hxFont has an 'H' and an 'x' but no CAP_HEIGHT or X_HEIGHT properties.
+// The 'x' is drawn inside a box taller than its ink.
+const hxFont = `STARTFONT 2.1
+FONT hx
+SIZE 8 75 75
+FONT_ASCENT 6
+FONT_DESCENT 2
+CHARS 2
+STARTCHAR H
+ENCODING 72
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+90
+90
+F0
+90
+90
+90
+ENDCHAR
+STARTCHAR x
+ENCODING 120
+DWIDTH 4 0
+BBX 4 6 0 0
+BITMAP
+00
+00
+90
+60
+60
+90
+ENDCHAR
+ENDFONT
+`
+
+func TestComputeCapXHeight(t *testing.T) {
+	f := mustParse(t, hxFont)
+	if f.CapHeight != 0 || f.XHeight != 0 {
+		t.Fatalf("CapHeight, XHeight = %d, %d before computing, want 0, 0", f.CapHeight, f.XHeight)
+	}
+
+	f.ComputeCapXHeight()
+	if f.CapHeight != 6 || f.XHeight != 4 {
+		t.Errorf("CapHeight, XHeight = %d, %d, want 6, 4", f.CapHeight, f.XHeight)
+	}
+
+	f.XHeight = 3
+	f.ComputeCapXHeight()
+	if f.XHeight != 3 {
+		t.Errorf("XHeight = %d, want the declared 3 kept", f.XHeight)
+	}
+}
+
+func TestPixelMetrics(t *testing.T) {
+	f := mustParse(t, testFont)
+	face := f.NewFace()
+
+	for _, r := range "ABg" {
+		px, ok := f.GlyphAdvancePixels(r)
+		a, _ := face.GlyphAdvance(r)
+		if !ok || px != a.Round() {
+			t.Errorf("GlyphAdvancePixels(%q) = %d, %v, want %d", r, px, ok, a.Round())
+		}
+
+		rect, ok := f.GlyphBoundsPixels(r)
+		bounds, _, _ := face.GlyphBounds(r)
+		want := image.Rect(bounds.Min.X.Round(), bounds.Min.Y.Round(), bounds.Max.X.Round(), bounds.Max.Y.Round())
+		if !ok || rect != want {
+			t.Errorf("GlyphBoundsPixels(%q) = %v, %v, want %v", r, rect, ok, want)
+		}
+	}
+}
+
+func TestMaxGlyphSize(t *testing.T) {
+	if w, h := mustParse(t, testFont).MaxGlyphSize(); w != 5 || h != 8 {
+		t.Errorf("MaxGlyphSize() = %d, %d, want 5, 8", w, h)
+	}
+	if w, h := (&Font{}).MaxGlyphSize(); w != 0 || h != 0 {
+		t.Errorf("empty MaxGlyphSize() = %d, %d, want 0, 0", w, h)
+	}
+}
+
+func TestAdvanceHistogram(t *testing.T) {
+	f := mustParse(t, testFont)
+	hist := f.AdvanceHistogram()
+	if len(hist) != 1 || hist[5] != 4 {
+		t.Errorf("AdvanceHistogram() = %v, want map[5:4]", hist)
+	}
+	if !f.IsMonospace() {
+		t.Error("IsMonospace() = false")
+	}
+
+	if mustParse(t, hxFont).IsMonospace() {
+		t.Error("proportional font reports IsMonospace() = true")
+	}
+}
+
+func TestEmMetrics(t *testing.T) {
+	ascent, descent := mustParse(t, testFont).EmMetrics()
+	if ascent != 0.75 || descent != 0.25 {
+		t.Errorf("EmMetrics() = %v, %v, want 0.75, 0.25", ascent, descent)
+	}
+
+	ascent, descent = (&Font{Ascent: 6, Descent: 2}).EmMetrics()
+	if ascent != 0 || descent != 0 {
+		t.Errorf("EmMetrics() without PixelSize = %v, %v, want 0, 0", ascent, descent)
+	}
+}
+
+func TestCoverageBitmap(t *testing.T) {
+	bits := mustParse(t, testFont).CoverageBitmap()
+	has := func(r rune) bool {
+		word := int(r / 64)
+		return word < len(bits) && bits[word]&(1<<uint(r%64)) != 0
+	}
+
+	for _, r := range " ABg" {
+		if !has(r) {
+			t.Errorf("bit for %q not set", r)
+		}
+	}
+	for _, r := range "Cabé" {
+		if has(r) {
+			t.Errorf("bit for %q set", r)
+		}
+	}
+	if len(bits) != 2 {
+		t.Errorf("bitset has %d words, want 2", len(bits))
+	}
+}
+
+func TestLineHeight(t *testing.T) {
+	f := mustParse(t, testFont)
+	if got := f.LineHeight(); got != 8 {
+		t.Errorf("LineHeight() = %d, want 8", got)
+	}
+
+	f.SetLineHeight(11)
+	if got := f.LineHeight(); got != 11 {
+		t.Errorf("LineHeight() = %d, want 11", got)
+	}
+	if got := f.NewFace().Metrics().Height.Round(); got != 11 {
+		t.Errorf("Metrics().Height = %d, want 11", got)
+	}
+	if got := f.MultilineBounds([]string{"A", "A"}, 0); got.Max.Y != 13 {
+		t.Errorf("MultilineBounds bottom = %d, want 13", got.Max.Y)
+	}
+
+	f.SetLineHeight(0)
+	if got := f.LineHeight(); got != 8 {
+		t.Errorf("LineHeight() after reset = %d, want 8", got)
+	}
+}
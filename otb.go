@@ -0,0 +1,442 @@
+package bdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf16"
+)
+
+// WriteOTB writes the font as an OpenType Bitmap (OTB) font containing only
+// bitmap strikes, suitable for installing on systems that no longer accept
+// BDF or PCF fonts.
+//
+// Only 1-bit fonts are supported. The output holds a single strike at the
+// font's pixel size, glyph metrics must fit the EBDT small metrics ranges,
+// and the character map is a single format 12 subtable.
+func (f *Font) WriteOTB(w io.Writer) error {
+	if f.BPP != 1 {
+		return fmt.Errorf("bdf: OTB export supports 1-bit fonts only, font is %d-bit", f.BPP)
+	}
+
+	ppem := f.PixelSize
+	if ppem <= 0 {
+		ppem = f.Ascent + f.Descent
+	}
+	if ppem <= 0 || ppem > 255 {
+		return fmt.Errorf("bdf: OTB export needs a pixel size between 1 and 255, got %d", ppem)
+	}
+
+	// One pixel is 64 font units.
+	const unit = 64
+
+	runes := f.sortedRunes()
+	glyphs := make([]*Character, 0, len(runes)+1)
+	notdef, _ := f.NotdefGlyph()
+	if notdef == nil {
+		notdef = &Character{}
+	}
+	glyphs = append(glyphs, notdef)
+	for _, r := range runes {
+		glyphs = append(glyphs, f.CharMap[r])
+	}
+
+	// EBDT: image format 1, small metrics with byte-aligned rows.
+	var ebdt bytes.Buffer
+	writeAll(&ebdt, uint32(0x00020000))
+	offsets := make([]uint32, 0, len(glyphs)+1)
+	var maxAdvance int
+	minLSB, minRSB, maxExtent := 0, 0, 0
+	for i, c := range glyphs {
+		var width, height int
+		if c.Alpha != nil {
+			width, height = c.Alpha.Rect.Dx(), c.Alpha.Rect.Dy()
+		}
+		bearingY := c.LowerPoint[1] + height
+		if width > 255 || height > 255 || c.Advance[0] < 0 || c.Advance[0] > 255 ||
+			c.LowerPoint[0] < -128 || c.LowerPoint[0] > 127 || bearingY < -128 || bearingY > 127 {
+			return fmt.Errorf("bdf: glyph %q metrics do not fit OTB small metrics", c.Name)
+		}
+
+		if c.Advance[0] > maxAdvance {
+			maxAdvance = c.Advance[0]
+		}
+		rsb := c.Advance[0] - c.LowerPoint[0] - width
+		if i == 0 || c.LowerPoint[0] < minLSB {
+			minLSB = c.LowerPoint[0]
+		}
+		if i == 0 || rsb < minRSB {
+			minRSB = rsb
+		}
+		if extent := c.LowerPoint[0] + width; extent > maxExtent {
+			maxExtent = extent
+		}
+
+		offsets = append(offsets, uint32(ebdt.Len()-4))
+		ebdt.Write([]byte{uint8(height), uint8(width), uint8(int8(c.LowerPoint[0])), uint8(int8(bearingY)), uint8(c.Advance[0])})
+		for _, row := range c.Rows() {
+			ebdt.Write(row)
+		}
+	}
+	offsets = append(offsets, uint32(ebdt.Len()-4))
+
+	// EBLC: one strike with one format 1 index subtable covering all glyphs.
+	var eblc bytes.Buffer
+	lastGlyph := uint16(len(glyphs) - 1)
+	indexTablesSize := uint32(8 + 8 + 4*len(offsets))
+	lineMetrics := otbLineMetrics{
+		Ascender:      int8(f.Ascent),
+		Descender:     int8(-f.Descent),
+		WidthMax:      uint8(maxAdvance),
+		CaretSlopeNum: 1,
+		MinOriginSB:   int8(minLSB),
+		MinAdvanceSB:  int8(minRSB),
+		MaxBeforeBL:   int8(f.Ascent),
+		MinAfterBL:    int8(-f.Descent),
+	}
+	writeAll(&eblc,
+		uint32(0x00020000), // version
+		uint32(1),          // numSizes
+		otbBitmapSize{
+			IndexSubTableArrayOffset: 8 + 48,
+			IndexTablesSize:          indexTablesSize,
+			NumberOfIndexSubTables:   1,
+			Hori:                     lineMetrics,
+			Vert:                     lineMetrics,
+			StartGlyphIndex:          0,
+			EndGlyphIndex:            lastGlyph,
+			PPEMX:                    uint8(ppem),
+			PPEMY:                    uint8(ppem),
+			BitDepth:                 1,
+			Flags:                    1, // horizontal metrics
+		},
+		// IndexSubTableArray
+		uint16(0), lastGlyph, uint32(8),
+		// IndexSubHeader: format 1 index, format 1 images
+		uint16(1), uint16(1), uint32(4),
+		offsets,
+	)
+
+	// cmap: a format 12 subtable shared by the Unicode and Windows records.
+	type group struct{ start, end, glyph uint32 }
+	var groups []group
+	for i, r := range runes {
+		gid := uint32(i + 1)
+		if n := len(groups); n > 0 && groups[n-1].end+1 == uint32(r) {
+			groups[n-1].end = uint32(r)
+			continue
+		}
+		groups = append(groups, group{uint32(r), uint32(r), gid})
+	}
+	var cmap bytes.Buffer
+	writeAll(&cmap,
+		uint16(0), uint16(2),
+		uint16(0), uint16(4), uint32(20),
+		uint16(3), uint16(10), uint32(20),
+		uint16(12), uint16(0), uint32(16+12*len(groups)), uint32(0), uint32(len(groups)),
+	)
+	for _, g := range groups {
+		writeAll(&cmap, g.start, g.end, g.glyph)
+	}
+
+	// hmtx
+	var hmtx bytes.Buffer
+	for _, c := range glyphs {
+		writeAll(&hmtx, uint16(c.Advance[0]*unit), int16(c.LowerPoint[0]*unit))
+	}
+
+	var head bytes.Buffer
+	writeAll(&head, otbHead{
+		Version:            0x00010000,
+		FontRevision:       0x00010000,
+		MagicNumber:        0x5F0F3CF5,
+		Flags:              0x000B,
+		UnitsPerEm:         uint16(ppem * unit),
+		XMin:               int16(minLSB * unit),
+		YMin:               int16(-f.Descent * unit),
+		XMax:               int16(maxExtent * unit),
+		YMax:               int16(f.Ascent * unit),
+		LowestRecPPEM:      uint16(ppem),
+		FontDirectionHint:  2,
+		IndexToLocFormat:   0,
+		GlyphDataFormat:    0,
+		CheckSumAdjustment: 0,
+	})
+
+	var hhea bytes.Buffer
+	writeAll(&hhea, otbHhea{
+		Version:             0x00010000,
+		Ascender:            int16(f.Ascent * unit),
+		Descender:           int16(-f.Descent * unit),
+		AdvanceWidthMax:     uint16(maxAdvance * unit),
+		MinLeftSideBearing:  int16(minLSB * unit),
+		MinRightSideBearing: int16(minRSB * unit),
+		XMaxExtent:          int16(maxExtent * unit),
+		CaretSlopeRise:      1,
+		NumberOfHMetrics:    uint16(len(glyphs)),
+	})
+
+	// Empty outlines, which TrueType-flavoured parsers expect to find.
+	var loca bytes.Buffer
+	writeAll(&loca, make([]uint16, len(glyphs)+1))
+
+	var maxp bytes.Buffer
+	writeAll(&maxp, uint32(0x00010000), uint16(len(glyphs)), [13]uint16{})
+
+	firstChar, lastChar := uint16(0xFFFF), uint16(0)
+	if len(runes) > 0 {
+		firstChar = clampUint16(runes[0])
+		lastChar = clampUint16(runes[len(runes)-1])
+	}
+	var os2 bytes.Buffer
+	writeAll(&os2, otbOS2{
+		Version:          1,
+		XAvgCharWidth:    int16(maxAdvance * unit),
+		UsWeightClass:    uint16(f.WeightClass()),
+		UsWidthClass:     5,
+		AchVendID:        [4]byte{' ', ' ', ' ', ' '},
+		FsSelection:      0x0040, // REGULAR
+		UsFirstCharIndex: firstChar,
+		UsLastCharIndex:  lastChar,
+		STypoAscender:    int16(f.Ascent * unit),
+		STypoDescender:   int16(-f.Descent * unit),
+		UsWinAscent:      uint16(f.Ascent * unit),
+		UsWinDescent:     uint16(f.Descent * unit),
+		UlCodePageRange1: 1, // Latin 1
+	})
+
+	var post bytes.Buffer
+	writeAll(&post, uint32(0x00030000), uint32(0), int16(-f.Descent*unit/2), int16(unit), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0))
+
+	family := f.Name
+	if x, ok := f.XLFD(); ok && x.Family != "" {
+		family = x.Family
+	}
+	name := otbNameTable(map[uint16]string{
+		1: family,
+		2: "Regular",
+		4: family,
+		6: postScriptName(family),
+	})
+
+	tables := map[string][]byte{
+		"EBDT": ebdt.Bytes(),
+		"EBLC": eblc.Bytes(),
+		"OS/2": os2.Bytes(),
+		"cmap": cmap.Bytes(),
+		"glyf": nil,
+		"head": head.Bytes(),
+		"hhea": hhea.Bytes(),
+		"hmtx": hmtx.Bytes(),
+		"loca": loca.Bytes(),
+		"maxp": maxp.Bytes(),
+		"name": name,
+		"post": post.Bytes(),
+	}
+
+	_, err := w.Write(buildSFNT(tables))
+	return err
+}
+
+func clampUint16(r rune) uint16 {
+	if r > 0xFFFF {
+		return 0xFFFF
+	}
+	return uint16(r)
+}
+
+func postScriptName(family string) string {
+	var b []byte
+	for _, r := range family {
+		if r > 32 && r < 127 && !bytes.ContainsRune([]byte("[](){}<>/%"), r) {
+			b = append(b, byte(r))
+		}
+	}
+	if len(b) == 0 {
+		return "Untitled"
+	}
+	if len(b) > 63 {
+		b = b[:63]
+	}
+	return string(b)
+}
+
+func otbNameTable(names map[uint16]string) []byte {
+	ids := make([]int, 0, len(names))
+	for id := range names {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	var records, data bytes.Buffer
+	for _, id := range ids {
+		encoded := utf16.Encode([]rune(names[uint16(id)]))
+		writeAll(&records, uint16(3), uint16(1), uint16(0x0409), uint16(id), uint16(2*len(encoded)), uint16(data.Len()))
+		writeAll(&data, encoded)
+	}
+
+	var buf bytes.Buffer
+	writeAll(&buf, uint16(0), uint16(len(ids)), uint16(6+12*len(ids)))
+	buf.Write(records.Bytes())
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+func buildSFNT(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	var buf bytes.Buffer
+	writeAll(&buf, uint32(0x00010000), uint16(numTables), uint16(searchRange), uint16(entrySelector), uint16(numTables*16-searchRange))
+
+	offset := 12 + 16*numTables
+	headOffset := 0
+	for _, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			headOffset = offset
+		}
+		buf.WriteString(tag)
+		writeAll(&buf, sfntChecksum(data), uint32(offset), uint32(len(data)))
+		offset += (len(data) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		data := tables[tag]
+		buf.Write(data)
+		buf.Write(make([]byte, ((len(data)+3)&^3)-len(data)))
+	}
+
+	out := buf.Bytes()
+	if headOffset > 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-sfntChecksum(out))
+	}
+	return out
+}
+
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// writeAll writes fixed-size values big-endian. Writes to a bytes.Buffer
+// cannot fail, so errors are not reported.
+func writeAll(buf *bytes.Buffer, values ...interface{}) {
+	for _, v := range values {
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+type otbLineMetrics struct {
+	Ascender      int8
+	Descender     int8
+	WidthMax      uint8
+	CaretSlopeNum int8
+	CaretSlopeDen int8
+	CaretOffset   int8
+	MinOriginSB   int8
+	MinAdvanceSB  int8
+	MaxBeforeBL   int8
+	MinAfterBL    int8
+	Padding       [2]int8
+}
+
+type otbBitmapSize struct {
+	IndexSubTableArrayOffset uint32
+	IndexTablesSize          uint32
+	NumberOfIndexSubTables   uint32
+	ColorRef                 uint32
+	Hori                     otbLineMetrics
+	Vert                     otbLineMetrics
+	StartGlyphIndex          uint16
+	EndGlyphIndex            uint16
+	PPEMX                    uint8
+	PPEMY                    uint8
+	BitDepth                 uint8
+	Flags                    int8
+}
+
+type otbHead struct {
+	Version            uint32
+	FontRevision       uint32
+	CheckSumAdjustment uint32
+	MagicNumber        uint32
+	Flags              uint16
+	UnitsPerEm         uint16
+	Created            int64
+	Modified           int64
+	XMin               int16
+	YMin               int16
+	XMax               int16
+	YMax               int16
+	MacStyle           uint16
+	LowestRecPPEM      uint16
+	FontDirectionHint  int16
+	IndexToLocFormat   int16
+	GlyphDataFormat    int16
+}
+
+type otbHhea struct {
+	Version             uint32
+	Ascender            int16
+	Descender           int16
+	LineGap             int16
+	AdvanceWidthMax     uint16
+	MinLeftSideBearing  int16
+	MinRightSideBearing int16
+	XMaxExtent          int16
+	CaretSlopeRise      int16
+	CaretSlopeRun       int16
+	CaretOffset         int16
+	Reserved            [4]int16
+	MetricDataFormat    int16
+	NumberOfHMetrics    uint16
+}
+
+type otbOS2 struct {
+	Version             uint16
+	XAvgCharWidth       int16
+	UsWeightClass       uint16
+	UsWidthClass        uint16
+	FsType              uint16
+	YSubscriptXSize     int16
+	YSubscriptYSize     int16
+	YSubscriptXOffset   int16
+	YSubscriptYOffset   int16
+	YSuperscriptXSize   int16
+	YSuperscriptYSize   int16
+	YSuperscriptXOffset int16
+	YSuperscriptYOffset int16
+	YStrikeoutSize      int16
+	YStrikeoutPosition  int16
+	SFamilyClass        int16
+	Panose              [10]uint8
+	UlUnicodeRange      [4]uint32
+	AchVendID           [4]byte
+	FsSelection         uint16
+	UsFirstCharIndex    uint16
+	UsLastCharIndex     uint16
+	STypoAscender       int16
+	STypoDescender      int16
+	STypoLineGap        int16
+	UsWinAscent         uint16
+	UsWinDescent        uint16
+	UlCodePageRange1    uint32
+	UlCodePageRange2    uint32
+}
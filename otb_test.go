@@ -0,0 +1,36 @@
+package bdf
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestWriteOTB(t *testing.T) {
+	var buf bytes.Buffer
+	if err := mustParse(t, testFont).WriteOTB(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	otb, err := sfnt.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("sfnt.Parse: %v", err)
+	}
+	// .notdef followed by the four encoded glyphs.
+	if n := otb.NumGlyphs(); n != 5 {
+		t.Errorf("NumGlyphs() = %d, want 5", n)
+	}
+
+	var b sfnt.Buffer
+	if i, err := otb.GlyphIndex(&b, 'B'); err != nil || i != 3 {
+		t.Errorf("GlyphIndex('B') = %d, %v, want 3", i, err)
+	}
+	if name, err := otb.Name(&b, sfnt.NameIDFamily); err != nil || name == "" {
+		t.Errorf("family name = %q, %v", name, err)
+	}
+
+	if err := mustParse(t, grayFont).WriteOTB(&buf); err == nil {
+		t.Error("WriteOTB of a 4-bit font succeeded")
+	}
+}
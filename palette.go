@@ -0,0 +1,44 @@
+package bdf
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+type paletteFace struct {
+	*Face
+	palette color.Palette
+}
+
+// NewPaletteFace returns a face whose glyph masks are paletted images, with
+// each of the font's (1 << BPP) levels indexing pal. Levels beyond the end
+// of pal use its last colour.
+func (f *Font) NewPaletteFace(pal color.Palette) font.Face {
+	return &paletteFace{
+		Face:    &Face{Font: f},
+		palette: pal,
+	}
+}
+
+func (p *paletteFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	dr, mask, maskp, advance, ok = p.Face.Glyph(dot, r)
+	if !ok || len(p.palette) == 0 {
+		return dr, mask, maskp, advance, ok
+	}
+
+	alpha := mask.(*image.Alpha)
+	levels := (1 << p.Font.BPP) - 1
+	paletted := image.NewPaletted(alpha.Rect, p.palette)
+	for i, v := range alpha.Pix {
+		level := (int(v)*levels + 127) / 255
+		if level >= len(p.palette) {
+			level = len(p.palette) - 1
+		}
+		paletted.Pix[i] = uint8(level)
+	}
+
+	return dr, paletted, maskp, advance, true
+}
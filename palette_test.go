@@ -0,0 +1,41 @@
+package bdf
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestPaletteFace(t *testing.T) {
+	f := mustParse(t, grayFont)
+	light, dark := color.RGBA{G: 0xff, A: 0xff}, color.RGBA{B: 0xff, A: 0xff}
+
+	// Levels 0-7 are light and 8-15 dark.
+	pal := make(color.Palette, 16)
+	for i := range pal {
+		pal[i] = light
+		if i >= 8 {
+			pal[i] = dark
+		}
+	}
+
+	_, mask, _, _, ok := f.NewPaletteFace(pal).Glyph(fixed.P(0, 6), 'C')
+	paletted, isPaletted := mask.(*image.Paletted)
+	if !ok || !isPaletted {
+		t.Fatalf("Glyph('C') mask = %T, want *image.Paletted", mask)
+	}
+	if paletted.Pix[0] != 15 || paletted.Pix[1] != 8 {
+		t.Errorf("levels = %v, want [15 8]", paletted.Pix)
+	}
+	if paletted.At(0, 0) != dark {
+		t.Errorf("full ink colour = %v, want %v", paletted.At(0, 0), dark)
+	}
+
+	// Levels past a short palette use its last colour.
+	_, mask, _, _, _ = f.NewPaletteFace(color.Palette{light, dark}).Glyph(fixed.P(0, 6), 'C')
+	if got := mask.(*image.Paletted).Pix; got[0] != 1 || got[1] != 1 {
+		t.Errorf("clamped levels = %v, want [1 1]", got)
+	}
+}
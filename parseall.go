@@ -0,0 +1,90 @@
+package bdf
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+)
+
+// ParseAllError collects the failures from ParseAll, keyed by path.
+type ParseAllError map[string]error
+
+func (e ParseAllError) Error() string {
+	paths := make([]string, 0, len(e))
+	for path := range e {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	msgs := make([]string, len(paths))
+	for i, path := range paths {
+		msgs[i] = fmt.Sprintf("%s: %v", path, e[path])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func parseFile(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// LoadFace parses the font file at path and returns a face for it along
+// with the font itself.
+func LoadFace(path string) (font.Face, *Font, error) {
+	f, err := parseFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f.NewFace(), f, nil
+}
+
+// ParseAll parses the files at paths using up to concurrency workers. Fonts
+// that parse successfully are returned even when others fail, in which case
+// the error is a ParseAllError.
+func ParseAll(paths []string, concurrency int) (map[string]*Font, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan string)
+	fonts := make(map[string]*Font, len(paths))
+	errs := make(ParseAllError)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				f, err := parseFile(path)
+
+				mu.Lock()
+				if err != nil {
+					errs[path] = err
+				} else {
+					fonts[path] = f
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return fonts, errs
+	}
+	return fonts, nil
+}
@@ -0,0 +1,71 @@
+package bdf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// writeFonts writes each source to a file in a temporary directory and
+// returns the paths.
+func writeFonts(t *testing.T, sources map[string]string) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make(map[string]string, len(sources))
+	for name, src := range sources {
+		paths[name] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[name], []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestParseAll(t *testing.T) {
+	paths := writeFonts(t, map[string]string{
+		"test.bdf":   testFont,
+		"latin1.bdf": latin1Font,
+		"gray.bdf":   grayFont,
+		"broken.bdf": strings.Replace(testFont, "CHARS 4", "CHARS four", 1),
+	})
+	list := []string{paths["test.bdf"], paths["latin1.bdf"], paths["gray.bdf"], paths["broken.bdf"], paths["broken.bdf"] + ".missing"}
+
+	fonts, err := ParseAll(list, 2)
+	if len(fonts) != 3 {
+		t.Errorf("got %d fonts, want 3", len(fonts))
+	}
+	if f := fonts[paths["latin1.bdf"]]; f == nil || !f.HasGlyph('é') {
+		t.Error("latin1.bdf missing or misparsed")
+	}
+
+	var errs ParseAllError
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("error = %v, want a ParseAllError with 2 entries", err)
+	}
+	if !errors.Is(errs[list[4]], os.ErrNotExist) {
+		t.Errorf("missing file error = %v", errs[list[4]])
+	}
+}
+
+func TestLoadFace(t *testing.T) {
+	paths := writeFonts(t, map[string]string{"test.bdf": testFont})
+
+	face, f, err := LoadFace(paths["test.bdf"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if face.(*Face).Font != f {
+		t.Error("face does not use the returned font")
+	}
+	if a, ok := face.GlyphAdvance('A'); !ok || a != fixed.I(5) {
+		t.Errorf("GlyphAdvance('A') = %v, %v, want 5", a, ok)
+	}
+
+	if _, _, err := LoadFace(paths["test.bdf"] + ".missing"); err == nil {
+		t.Error("LoadFace of a missing file succeeded")
+	}
+}
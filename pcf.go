@@ -0,0 +1,277 @@
+package bdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	pcfProperties   = 1 << 0
+	pcfAccelerators = 1 << 1
+	pcfMetrics      = 1 << 2
+	pcfBitmaps      = 1 << 3
+	pcfBDFEncodings = 1 << 5
+
+	// Big-endian bytes, most significant bit first, rows padded to a byte.
+	pcfFormat = 1<<2 | 1<<3
+)
+
+type pcfMetric struct {
+	LeftBearing  int16
+	RightBearing int16
+	Width        int16
+	Ascent       int16
+	Descent      int16
+	Attributes   uint16
+}
+
+// WritePCF writes the font in the X11 Portable Compiled Font format.
+//
+// Only 1-bit fonts are supported. The output holds the properties,
+// accelerators, metrics, bitmaps and encodings tables; ink metrics, glyph
+// names and scalable widths are omitted. The properties table carries only
+// FONT, FONT_ASCENT, FONT_DESCENT and PIXEL_SIZE.
+func (f *Font) WritePCF(w io.Writer) error {
+	if f.BPP != 1 {
+		return fmt.Errorf("bdf: PCF export supports 1-bit fonts only, font is %d-bit", f.BPP)
+	}
+
+	metrics := make([]pcfMetric, len(f.Characters))
+	var minBounds, maxBounds pcfMetric
+	for i, c := range f.Characters {
+		var width, height int
+		if c.Alpha != nil {
+			width, height = c.Alpha.Rect.Dx(), c.Alpha.Rect.Dy()
+		}
+		m := pcfMetric{
+			LeftBearing:  int16(c.LowerPoint[0]),
+			RightBearing: int16(c.LowerPoint[0] + width),
+			Width:        int16(c.Advance[0]),
+			Ascent:       int16(c.LowerPoint[1] + height),
+			Descent:      int16(-c.LowerPoint[1]),
+		}
+		metrics[i] = m
+
+		if i == 0 {
+			minBounds, maxBounds = m, m
+			continue
+		}
+		minBounds.LeftBearing = min16(minBounds.LeftBearing, m.LeftBearing)
+		minBounds.RightBearing = min16(minBounds.RightBearing, m.RightBearing)
+		minBounds.Width = min16(minBounds.Width, m.Width)
+		minBounds.Ascent = min16(minBounds.Ascent, m.Ascent)
+		minBounds.Descent = min16(minBounds.Descent, m.Descent)
+		maxBounds.LeftBearing = max16(maxBounds.LeftBearing, m.LeftBearing)
+		maxBounds.RightBearing = max16(maxBounds.RightBearing, m.RightBearing)
+		maxBounds.Width = max16(maxBounds.Width, m.Width)
+		maxBounds.Ascent = max16(maxBounds.Ascent, m.Ascent)
+		maxBounds.Descent = max16(maxBounds.Descent, m.Descent)
+	}
+
+	tables := map[int32][]byte{
+		pcfProperties:   f.pcfProperties(),
+		pcfAccelerators: f.pcfAccelerators(minBounds, maxBounds),
+		pcfMetrics:      pcfMetricsTable(metrics),
+		pcfBitmaps:      f.pcfBitmaps(),
+		pcfBDFEncodings: f.pcfEncodings(),
+	}
+	types := []int32{pcfProperties, pcfAccelerators, pcfMetrics, pcfBitmaps, pcfBDFEncodings}
+
+	var buf bytes.Buffer
+	buf.WriteString("\x01fcp")
+	binary.Write(&buf, binary.LittleEndian, int32(len(types)))
+	offset := 8 + 16*len(types)
+	for _, t := range types {
+		binary.Write(&buf, binary.LittleEndian, []int32{t, pcfFormat, int32(len(tables[t])), int32(offset)})
+		offset += (len(tables[t]) + 3) &^ 3
+	}
+	for _, t := range types {
+		buf.Write(tables[t])
+		buf.Write(make([]byte, ((len(tables[t])+3)&^3)-len(tables[t])))
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pcfTable starts a table with its format, which is always little-endian.
+func pcfTable() *bytes.Buffer {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(pcfFormat))
+	return &buf
+}
+
+func (f *Font) pcfProperties() []byte {
+	type property struct {
+		name   string
+		str    string
+		value  int32
+		isText bool
+	}
+	props := []property{
+		{name: "FONT", str: f.Name, isText: true},
+		{name: "FONT_ASCENT", value: int32(f.Ascent)},
+		{name: "FONT_DESCENT", value: int32(f.Descent)},
+		{name: "PIXEL_SIZE", value: int32(f.PixelSize)},
+	}
+
+	var strs bytes.Buffer
+	buf := pcfTable()
+	writeAll(buf, int32(len(props)))
+	for _, p := range props {
+		nameOffset := int32(strs.Len())
+		strs.WriteString(p.name)
+		strs.WriteByte(0)
+
+		value := p.value
+		var isText int8
+		if p.isText {
+			isText = 1
+			value = int32(strs.Len())
+			strs.WriteString(p.str)
+			strs.WriteByte(0)
+		}
+		writeAll(buf, nameOffset, isText, value)
+	}
+	if n := len(props) & 3; n != 0 {
+		buf.Write(make([]byte, 4-n))
+	}
+	writeAll(buf, int32(strs.Len()))
+	buf.Write(strs.Bytes())
+
+	return buf.Bytes()
+}
+
+func (f *Font) pcfAccelerators(minBounds, maxBounds pcfMetric) []byte {
+	maxOverlap := maxBounds.RightBearing - minBounds.Width
+	noOverlap := maxOverlap <= minBounds.LeftBearing
+	constantMetrics := minBounds == maxBounds
+	terminalFont := constantMetrics && minBounds.LeftBearing >= 0 && minBounds.RightBearing <= minBounds.Width
+	constantWidth := minBounds.Width == maxBounds.Width
+	inkInside := maxBounds.Ascent <= int16(f.Ascent) && maxBounds.Descent <= int16(f.Descent)
+
+	buf := pcfTable()
+	writeAll(buf,
+		boolByte(noOverlap),
+		boolByte(constantMetrics),
+		boolByte(terminalFont),
+		boolByte(constantWidth),
+		boolByte(inkInside),
+		uint8(0), // inkMetrics
+		uint8(0), // drawDirection: left to right
+		uint8(0), // padding
+		int32(f.Ascent),
+		int32(f.Descent),
+		int32(maxOverlap),
+		minBounds,
+		maxBounds,
+	)
+	return buf.Bytes()
+}
+
+func pcfMetricsTable(metrics []pcfMetric) []byte {
+	buf := pcfTable()
+	writeAll(buf, int32(len(metrics)), metrics)
+	return buf.Bytes()
+}
+
+func (f *Font) pcfBitmaps() []byte {
+	var data bytes.Buffer
+	offsets := make([]int32, len(f.Characters))
+	var sizes [4]int32
+	for i := range f.Characters {
+		offsets[i] = int32(data.Len())
+		rows := f.Characters[i].Rows()
+		for _, row := range rows {
+			data.Write(row)
+		}
+		for p := range sizes {
+			pad := 1 << p
+			for _, row := range rows {
+				sizes[p] += int32((len(row) + pad - 1) / pad * pad)
+			}
+		}
+	}
+
+	buf := pcfTable()
+	writeAll(buf, int32(len(f.Characters)), offsets, sizes)
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+func (f *Font) pcfEncodings() []byte {
+	minByte1, maxByte1 := 0xFF, 0
+	minByte2, maxByte2 := 0xFF, 0
+	for _, c := range f.Characters {
+		if c.RawEncoding < 0 || c.RawEncoding > 0xFFFF {
+			continue
+		}
+		b1, b2 := c.RawEncoding>>8, c.RawEncoding&0xFF
+		minByte1, maxByte1 = minInt(minByte1, b1), maxInt(maxByte1, b1)
+		minByte2, maxByte2 = minInt(minByte2, b2), maxInt(maxByte2, b2)
+	}
+	if minByte1 > maxByte1 {
+		minByte1, maxByte1, minByte2, maxByte2 = 0, 0, 0, 0
+	}
+
+	cols := maxByte2 - minByte2 + 1
+	indices := make([]uint16, (maxByte1-minByte1+1)*cols)
+	for i := range indices {
+		indices[i] = 0xFFFF
+	}
+
+	defaultChar := uint16(0xFFFF)
+	def, _ := f.NotdefGlyph()
+	for i := range f.Characters {
+		c := &f.Characters[i]
+		if c.RawEncoding < 0 || c.RawEncoding > 0xFFFF {
+			continue
+		}
+		b1, b2 := c.RawEncoding>>8, c.RawEncoding&0xFF
+		indices[(b1-minByte1)*cols+b2-minByte2] = uint16(i)
+		if c == def {
+			defaultChar = uint16(c.RawEncoding)
+		}
+	}
+
+	buf := pcfTable()
+	writeAll(buf, int16(minByte2), int16(maxByte2), int16(minByte1), int16(maxByte1), defaultChar, indices)
+	return buf.Bytes()
+}
+
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func min16(a, b int16) int16 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max16(a, b int16) int16 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
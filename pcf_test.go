@@ -0,0 +1,41 @@
+package bdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWritePCF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := mustParse(t, testFont).WritePCF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if magic := string(data[:4]); magic != "\x01fcp" {
+		t.Fatalf("magic = %q, want %q", magic, "\x01fcp")
+	}
+	count := binary.LittleEndian.Uint32(data[4:])
+	if count != 5 {
+		t.Fatalf("table count = %d, want 5", count)
+	}
+
+	want := []uint32{pcfProperties, pcfAccelerators, pcfMetrics, pcfBitmaps, pcfBDFEncodings}
+	for i := range want {
+		entry := data[8+16*i:]
+		typ := binary.LittleEndian.Uint32(entry)
+		size := binary.LittleEndian.Uint32(entry[8:])
+		offset := binary.LittleEndian.Uint32(entry[12:])
+		if typ != want[i] {
+			t.Errorf("table %d type = %#x, want %#x", i, typ, want[i])
+		}
+		if offset%4 != 0 || int(offset+size) > len(data) {
+			t.Errorf("table %d at %d+%d lies outside the %d-byte file", i, offset, size, len(data))
+		}
+	}
+
+	if err := mustParse(t, grayFont).WritePCF(&buf); err == nil {
+		t.Error("WritePCF of a 4-bit font succeeded")
+	}
+}
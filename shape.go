@@ -0,0 +1,59 @@
+package bdf
+
+import (
+	"golang.org/x/image/math/fixed"
+)
+
+// GlyphPlacement positions one rune of a shaped string.
+type GlyphPlacement struct {
+	Rune rune
+
+	// Dot is the glyph's origin relative to the start of the string.
+	Dot fixed.Int26_6
+
+	Advance fixed.Int26_6
+}
+
+// maxShapedStrings bounds the ShapeCached cache. When it is full the cache
+// is emptied and starts over.
+const maxShapedStrings = 1024
+
+// ShapeCached lays s out on a single line, applying the font's Kerning
+// pairs, and caches the result by string. Up to maxShapedStrings strings are
+// kept. The cache is dropped by InvalidateCache, which OverrideAdvance and
+// ImportKerningFromProperties call; direct edits to Kerning are not noticed
+// until it is called. Callers must not modify the returned slice.
+func (f *Font) ShapeCached(s string) []GlyphPlacement {
+	if f.advances != nil {
+		f.advances.mu.RLock()
+		shaped, ok := f.advances.shapes[s]
+		f.advances.mu.RUnlock()
+		if ok {
+			return shaped
+		}
+	}
+
+	shaped := make([]GlyphPlacement, 0, len(s))
+	var dot fixed.Int26_6
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			dot += fixed.I(f.Kerning[[2]rune{prev, r}])
+		}
+		a, _ := f.advance(r)
+		shaped = append(shaped, GlyphPlacement{Rune: r, Dot: dot, Advance: a})
+		dot += a
+		prev = r
+	}
+
+	if f.advances != nil {
+		f.advances.mu.Lock()
+		if len(f.advances.shapes) >= maxShapedStrings {
+			f.advances.shapes = make(map[string][]GlyphPlacement)
+		}
+		f.advances.shapes[s] = shaped
+		f.advances.mu.Unlock()
+	}
+
+	return shaped
+}
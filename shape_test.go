@@ -0,0 +1,63 @@
+package bdf
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestShapeCached(t *testing.T) {
+	f := mustParse(t, testFont)
+	f.Kerning = map[[2]rune]int{{'A', 'B'}: -1}
+
+	want := []GlyphPlacement{
+		{Rune: 'A', Dot: 0, Advance: fixed.I(5)},
+		{Rune: 'B', Dot: fixed.I(4), Advance: fixed.I(5)},
+		{Rune: 'g', Dot: fixed.I(9), Advance: fixed.I(5)},
+	}
+	for i := 0; i < 2; i++ {
+		got := f.ShapeCached("ABg")
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("ShapeCached(\"ABg\") = %v, want %v", got, want)
+		}
+	}
+
+	f.OverrideAdvance('A', 7)
+	if got := f.ShapeCached("ABg")[1].Dot; got != fixed.I(6) {
+		t.Errorf("'B' dot after OverrideAdvance = %v, want 6", got)
+	}
+
+	f.Kerning[[2]rune{'A', 'B'}] = 0
+	f.InvalidateCache()
+	if got := f.ShapeCached("ABg")[1].Dot; got != fixed.I(7) {
+		t.Errorf("'B' dot after editing Kerning = %v, want 7", got)
+	}
+}
+
+func BenchmarkShapeCached(b *testing.B) {
+	f := mustParse(b, testFont)
+	for i := 0; i < b.N; i++ {
+		f.ShapeCached(measureText)
+	}
+}
+
+// BenchmarkShapeUncached shapes the same text with the cache dropped each
+// time, for comparison with BenchmarkShapeCached.
+func BenchmarkShapeUncached(b *testing.B) {
+	f := mustParse(b, testFont)
+	for i := 0; i < b.N; i++ {
+		f.advances.shapes = make(map[string][]GlyphPlacement)
+		f.ShapeCached(measureText)
+	}
+}
+
+func TestShapeCachedBounded(t *testing.T) {
+	f := mustParse(t, testFont)
+	for i := 0; i < 3*maxShapedStrings; i++ {
+		f.ShapeCached(fmt.Sprint(i))
+	}
+	if n := len(f.advances.shapes); n > maxShapedStrings {
+		t.Errorf("cache holds %d strings, want at most %d", n, maxShapedStrings)
+	}
+}
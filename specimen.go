@@ -0,0 +1,69 @@
+package bdf
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// SpecimenSheet renders every encoded glyph, in order of encoding, into a
+// grid cols cells wide. Each cell is captioned with its code point.
+func (f *Font) SpecimenSheet(cols int) *image.RGBA {
+	if cols <= 0 {
+		cols = 16
+	}
+
+	const pad = 2
+	caption := basicfont.Face7x13
+
+	runes := f.sortedRunes()
+	labels := make([]string, len(runes))
+	labelWidth := 0
+	for i, r := range runes {
+		labels[i] = fmt.Sprintf("U+%04X", r)
+		if w := font.MeasureString(caption, labels[i]).Ceil(); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	glyphWidth, glyphHeight := f.MaxGlyphSize()
+	for _, r := range runes {
+		if a := f.CharMap[r].Advance[0]; a > glyphWidth {
+			glyphWidth = a
+		}
+	}
+	if h := f.Ascent + f.Descent; h > glyphHeight {
+		glyphHeight = h
+	}
+
+	cellWidth := glyphWidth
+	if labelWidth > cellWidth {
+		cellWidth = labelWidth
+	}
+	cellWidth += 2 * pad
+	cellHeight := glyphHeight + caption.Height + 3*pad
+
+	rows := (len(runes) + cols - 1) / cols
+	img := image.NewRGBA(image.Rect(0, 0, cols*cellWidth, rows*cellHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	glyphs := font.Drawer{Dst: img, Src: image.Black, Face: f.NewFace()}
+	captions := font.Drawer{Dst: img, Src: image.NewUniform(color.Gray{Y: 0x80}), Face: caption}
+	for i, r := range runes {
+		x := (i%cols)*cellWidth + pad
+		y := (i/cols)*cellHeight + pad
+
+		glyphs.Dot = fixed.P(x, y+f.Ascent)
+		glyphs.DrawString(string(r))
+
+		captions.Dot = fixed.P(x, y+glyphHeight+pad+caption.Ascent)
+		captions.DrawString(labels[i])
+	}
+
+	return img
+}
@@ -0,0 +1,20 @@
+package bdf
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSpecimenSheet(t *testing.T) {
+	// Cells fit the 42-pixel caption "U+0020" and two paddings across, and
+	// the 8-pixel glyph, a 13-pixel caption and three paddings down.
+	img := mustParse(t, testFont).SpecimenSheet(3)
+	if want := image.Rect(0, 0, 3*46, 2*27); img.Bounds() != want {
+		t.Errorf("bounds = %v, want %v", img.Bounds(), want)
+	}
+
+	img = mustParse(t, testFont).SpecimenSheet(0)
+	if want := image.Rect(0, 0, 16*46, 27); img.Bounds() != want {
+		t.Errorf("default bounds = %v, want %v", img.Bounds(), want)
+	}
+}
@@ -0,0 +1,83 @@
+package bdf
+
+import (
+	"image"
+	"strings"
+)
+
+// WrapString greedily breaks s into lines no wider than maxWidth pixels,
+// breaking at spaces and at explicit newlines. Words wider than maxWidth are
+// broken between runes, and a rune wider than maxWidth gets a line of its
+// own, so maxWidth may even be zero or negative.
+func (f *Font) WrapString(s string, maxWidth int) []string {
+	width := func(s string) int {
+		return f.MeasureString(s).Ceil()
+	}
+	space := width(" ")
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line, lineWidth := "", 0
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := width(word)
+			if line != "" && lineWidth+space+wordWidth <= maxWidth {
+				line += " " + word
+				lineWidth += space + wordWidth
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			for wordWidth > maxWidth {
+				head := splitToWidth(f, word, maxWidth)
+				if head == word {
+					break
+				}
+				lines = append(lines, head)
+				word = word[len(head):]
+				wordWidth = width(word)
+			}
+			line, lineWidth = word, wordWidth
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// splitToWidth returns the longest prefix of s no wider than maxWidth, but
+// always at least one rune.
+func splitToWidth(f *Font, s string, maxWidth int) string {
+	w := 0
+	for i, r := range s {
+		a, _ := f.advance(r)
+		w += a.Ceil()
+		if w > maxWidth && i > 0 {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// MultilineBounds returns the box covering lines drawn lineSpacing pixels
+// apart, baseline to baseline, relative to the first line's origin. A
+// lineSpacing of zero uses the font's LineHeight.
+func (f *Font) MultilineBounds(lines []string, lineSpacing int) image.Rectangle {
+	if len(lines) == 0 {
+		return image.Rectangle{}
+	}
+	if lineSpacing == 0 {
+		lineSpacing = f.LineHeight()
+	}
+
+	width := 0
+	for _, line := range lines {
+		if w := f.MeasureString(line).Ceil(); w > width {
+			width = w
+		}
+	}
+
+	return image.Rect(0, -f.Ascent, width, (len(lines)-1)*lineSpacing+f.Descent)
+}
@@ -0,0 +1,61 @@
+package bdf
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestWrapString(t *testing.T) {
+	f := mustParse(t, testFont)
+
+	got := f.WrapString("AB gA\nBBBBB", 12)
+	want := []string{"AB", "gA", "BB", "BB", "B"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapString = %q, want %q", got, want)
+	}
+
+	// Runes wider than the limit are placed one per line.
+	for _, maxWidth := range []int{3, 0, -1} {
+		got := f.WrapString("AB g", maxWidth)
+		want := []string{"A", "B", "g"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WrapString(%q, %d) = %q, want %q", "AB g", maxWidth, got, want)
+		}
+	}
+}
+
+func TestWrapStringMarks(t *testing.T) {
+	f := mustParse(t, marksFont)
+
+	// Marks never push a word over the limit, so they stay with their base.
+	got := f.WrapString("A\u0301 B\u0301", 9)
+	want := []string{"A\u0301", "B\u0301"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapString = %q, want %q", got, want)
+	}
+	if got := f.WrapString("A\u0301 B\u0301", 11); len(got) != 1 {
+		t.Errorf("WrapString = %q, want one line", got)
+	}
+	got = f.WrapString("AB\u0301", 5)
+	want = []string{"A", "B\u0301"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapString of a long word = %q, want %q", got, want)
+	}
+}
+
+func TestMultilineBounds(t *testing.T) {
+	f := mustParse(t, testFont)
+	if got, want := f.MultilineBounds([]string{"AB", "A", "ABg"}, 10), image.Rect(0, -6, 15, 22); got != want {
+		t.Errorf("MultilineBounds = %v, want %v", got, want)
+	}
+	if got := f.MultilineBounds(nil, 0); got != (image.Rectangle{}) {
+		t.Errorf("MultilineBounds(nil) = %v, want empty", got)
+	}
+
+	// The acute pulls the pen back two pixels: 5 - 2 + 5.
+	m := mustParse(t, marksFont)
+	if got, want := m.MultilineBounds([]string{"A\u0301B", "\u0301"}, 0), image.Rect(0, -6, 8, 10); got != want {
+		t.Errorf("MultilineBounds with marks = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package bdf
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+)
+
+// NewFaceWithTofu returns a face that draws a hollow box, one advance of
+// the font's widest glyph across and its ascent plus descent high, for runes
+// that are neither in the font nor covered by DefaultChar.
+func (f *Font) NewFaceWithTofu() font.Face {
+	return &Face{
+		Font: f,
+		tofu: f.tofuGlyph(),
+	}
+}
+
+func (f *Font) tofuGlyph() *Character {
+	advance := 0
+	for a := range f.AdvanceHistogram() {
+		if a > advance {
+			advance = a
+		}
+	}
+	if advance <= 0 {
+		advance = (f.Ascent + f.Descent + 1) / 2
+	}
+
+	// Leave a column free so adjacent boxes stay apart.
+	w, h := advance-1, f.Ascent+f.Descent
+	if w < 1 {
+		w = 1
+	}
+	alpha := image.NewAlpha(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				alpha.Pix[y*alpha.Stride+x] = 0xff
+			}
+		}
+	}
+
+	return &Character{
+		Name:         ".tofu",
+		Encoding:     -1,
+		RawEncoding:  -1,
+		Advance:      [2]int{advance, 0},
+		ExactAdvance: [2]float64{float64(advance), 0},
+		Alpha:        alpha,
+		LowerPoint:   [2]int{0, -f.Descent},
+	}
+}
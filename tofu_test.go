@@ -0,0 +1,42 @@
+package bdf
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestNewFaceWithTofu(t *testing.T) {
+	// latin1Font has no DEFAULT_CHAR glyph, so missing runes are not drawn.
+	f := mustParse(t, latin1Font)
+	if _, _, _, _, ok := f.NewFace().Glyph(fixed.P(0, 6), 'Z'); ok {
+		t.Fatal("plain face found a glyph for 'Z'")
+	}
+
+	face := f.NewFaceWithTofu()
+	dr, mask, _, advance, ok := face.Glyph(fixed.P(0, 6), 'Z')
+	if !ok {
+		t.Fatal("Glyph('Z') found no tofu")
+	}
+	if advance != fixed.I(5) || dr != image.Rect(0, 0, 4, 8) {
+		t.Errorf("tofu at %v advancing %v, want (0,0)-(4,8) advancing 5", dr, advance)
+	}
+
+	// The box is hollow: inked on its edges and clear inside.
+	box := mask.(*image.Alpha)
+	for _, p := range []image.Point{{0, 0}, {3, 0}, {0, 7}, {3, 7}, {1, 0}, {0, 4}} {
+		if box.AlphaAt(p.X, p.Y).A == 0 {
+			t.Errorf("edge pixel %v is clear", p)
+		}
+	}
+	for _, p := range []image.Point{{1, 1}, {2, 4}, {2, 6}} {
+		if box.AlphaAt(p.X, p.Y).A != 0 {
+			t.Errorf("inner pixel %v is inked", p)
+		}
+	}
+
+	if _, mask, _, _, _ := face.Glyph(fixed.P(0, 6), 'A'); mask != f.CharMap['A'].Alpha {
+		t.Error("Glyph('A') did not use the font's glyph")
+	}
+}
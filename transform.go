@@ -0,0 +1,318 @@
+package bdf
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// clone returns a deep copy of f whose CharMap points into its own
+// Characters.
+func (f *Font) clone() *Font {
+	c := f.cloneMetadata()
+	c.Characters = make([]Character, len(f.Characters))
+	index := make(map[*Character]int, len(f.Characters))
+	for i := range f.Characters {
+		c.Characters[i] = f.Characters[i]
+		if a := f.Characters[i].Alpha; a != nil {
+			c.Characters[i].Alpha = &image.Alpha{
+				Pix:    append([]byte(nil), a.Pix...),
+				Stride: a.Stride,
+				Rect:   a.Rect,
+			}
+		}
+		index[&f.Characters[i]] = i
+	}
+
+	if f.CharMap != nil {
+		c.CharMap = make(map[rune]*Character, len(f.CharMap))
+		for r, ch := range f.CharMap {
+			c.CharMap[r] = &c.Characters[index[ch]]
+		}
+	}
+	c.buildASCII()
+
+	return c
+}
+
+// cloneMetadata returns a copy of f with its own properties, kerning and
+// advance overrides but no glyphs.
+func (f *Font) cloneMetadata() *Font {
+	c := *f
+	c.Characters = nil
+	c.CharMap = nil
+
+	if f.Properties != nil {
+		c.Properties = make(map[string]string, len(f.Properties))
+		for k, v := range f.Properties {
+			c.Properties[k] = v
+		}
+	}
+
+	if f.Kerning != nil {
+		c.Kerning = make(map[[2]rune]int, len(f.Kerning))
+		for pair, k := range f.Kerning {
+			c.Kerning[pair] = k
+		}
+	}
+
+	if f.advanceOverrides != nil {
+		c.advanceOverrides = make(map[rune]int, len(f.advanceOverrides))
+		for r, px := range f.advanceOverrides {
+			c.advanceOverrides[r] = px
+		}
+	}
+	c.advances = newAdvanceCache()
+
+	return &c
+}
+
+// SetBaseline returns a copy of f with its baseline moved so that the ascent
+// is the given value. The line height is unchanged and every glyph keeps its
+// position within the line, so glyph offsets shift with the baseline.
+func (f *Font) SetBaseline(ascent int) *Font {
+	c := f.clone()
+
+	delta := ascent - f.Ascent
+	c.Ascent = ascent
+	c.Descent = f.Descent - delta
+	for i := range c.Characters {
+		c.Characters[i].LowerPoint[1] += delta
+	}
+
+	return c
+}
+
+// ToMonospace returns a copy of f in which every glyph advances by
+// cellWidth and is centred within its cell.
+func (f *Font) ToMonospace(cellWidth int) *Font {
+	return f.toMonospace(cellWidth, true)
+}
+
+// ToMonospaceLeft is like ToMonospace but places each glyph at the left
+// edge of its cell.
+func (f *Font) ToMonospaceLeft(cellWidth int) *Font {
+	return f.toMonospace(cellWidth, false)
+}
+
+func (f *Font) toMonospace(cellWidth int, center bool) *Font {
+	c := f.clone()
+	c.advanceOverrides = nil
+
+	for i := range c.Characters {
+		ch := &c.Characters[i]
+		ch.Advance[0] = cellWidth
+		ch.ExactAdvance[0] = float64(cellWidth)
+		ch.LowerPoint[0] = 0
+		if center && ch.Alpha != nil {
+			ch.LowerPoint[0] = (cellWidth - ch.Alpha.Rect.Dx()) / 2
+		}
+	}
+	c.InvalidateCache()
+
+	return c
+}
+
+// DownscaleSmooth returns a copy of f scaled to targetPx pixels per em,
+// supersampling each glyph so that partially covered pixels get intermediate
+// alpha values. The result is an 8-bit font.
+func (f *Font) DownscaleSmooth(targetPx int) *Font {
+	const samples = 8
+
+	sourcePx := f.PixelSize
+	if sourcePx <= 0 {
+		sourcePx = f.Ascent + f.Descent
+	}
+	if sourcePx <= 0 || targetPx <= 0 {
+		return f.clone()
+	}
+	scale := float64(targetPx) / float64(sourcePx)
+
+	c := f.clone()
+	c.BPP = 8
+	c.PixelSize = targetPx
+	c.Size = int(math.Round(float64(f.Size) * scale))
+	c.Ascent = int(math.Round(float64(f.Ascent) * scale))
+	c.Descent = int(math.Round(float64(f.Descent) * scale))
+	c.CapHeight = int(math.Round(float64(f.CapHeight) * scale))
+	c.XHeight = int(math.Round(float64(f.XHeight) * scale))
+	for r, px := range c.advanceOverrides {
+		c.advanceOverrides[r] = int(math.Round(float64(px) * scale))
+	}
+	for pair, k := range c.Kerning {
+		c.Kerning[pair] = int(math.Round(float64(k) * scale))
+	}
+
+	for i := range c.Characters {
+		ch := &c.Characters[i]
+		src := f.Characters[i].Alpha
+		for j := range ch.ExactAdvance {
+			ch.ExactAdvance[j] *= scale
+			ch.Advance[j] = int(math.Round(ch.ExactAdvance[j]))
+		}
+		if src == nil {
+			continue
+		}
+
+		// Work in y-down coordinates relative to the origin on the baseline.
+		lx, ly := ch.LowerPoint[0], ch.LowerPoint[1]
+		w, h := src.Rect.Dx(), src.Rect.Dy()
+		x0 := int(math.Floor(float64(lx) * scale))
+		x1 := int(math.Ceil(float64(lx+w) * scale))
+		y0 := int(math.Floor(float64(-(ly + h)) * scale))
+		y1 := int(math.Ceil(float64(-ly) * scale))
+		if w == 0 || h == 0 {
+			x1, y1 = x0, y0
+		}
+
+		dst := image.NewAlpha(image.Rect(0, 0, x1-x0, y1-y0))
+		for ty := y0; ty < y1; ty++ {
+			for tx := x0; tx < x1; tx++ {
+				sum := 0
+				for sy := 0; sy < samples; sy++ {
+					for sx := 0; sx < samples; sx++ {
+						col := int(math.Floor((float64(tx)+(float64(sx)+0.5)/samples)/scale)) - lx
+						row := int(math.Floor((float64(ty)+(float64(sy)+0.5)/samples)/scale)) + ly + h
+						if col >= 0 && col < w && row >= 0 && row < h {
+							sum += int(src.Pix[row*src.Stride+col])
+						}
+					}
+				}
+				dst.Pix[(ty-y0)*dst.Stride+tx-x0] = uint8((sum + samples*samples/2) / (samples * samples))
+			}
+		}
+
+		ch.Alpha = dst
+		ch.LowerPoint = [2]int{x0, -y1}
+	}
+	// clone cached the unscaled advances.
+	c.InvalidateCache()
+
+	return c
+}
+
+// RequantizeBPP returns a copy of f reduced to target bits per pixel, with
+// each alpha value mapped to the nearest of the new levels.
+func (f *Font) RequantizeBPP(target int) (*Font, error) {
+	switch target {
+	case 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("bdf: unsupported bit depth %d", target)
+	}
+	if target > f.BPP {
+		return nil, fmt.Errorf("bdf: cannot requantize a %d-bit font to %d bits", f.BPP, target)
+	}
+
+	c := f.clone()
+	c.BPP = target
+	for i := range c.Characters {
+		if a := c.Characters[i].Alpha; a != nil {
+			c.Characters[i].Alpha = requantize(a, target)
+		}
+	}
+
+	return c, nil
+}
+
+// InvertAll inverts the coverage of every glyph in place. See
+// Character.Invert.
+func (f *Font) InvertAll() {
+	for i := range f.Characters {
+		f.Characters[i].Invert()
+	}
+}
+
+// addCharacter appends c to Characters and indexes it under its encoding,
+// keeping CharMap pointing into the possibly reallocated slice.
+func (f *Font) addCharacter(c Character) *Character {
+	index := make(map[*Character]int, len(f.Characters))
+	for i := range f.Characters {
+		index[&f.Characters[i]] = i
+	}
+
+	f.Characters = append(f.Characters, c)
+	added := &f.Characters[len(f.Characters)-1]
+	if f.CharMap != nil {
+		for r, ch := range f.CharMap {
+			f.CharMap[r] = &f.Characters[index[ch]]
+		}
+		f.CharMap[c.Encoding] = added
+	}
+	f.InvalidateCache()
+
+	return added
+}
+
+// EnsureSpace adds a blank space glyph with the given advance if the font
+// does not already have one.
+func (f *Font) EnsureSpace(width int) {
+	if f.HasGlyph(' ') {
+		return
+	}
+
+	f.addCharacter(Character{
+		Name:         "space",
+		Encoding:     ' ',
+		RawEncoding:  ' ',
+		Advance:      [2]int{width, 0},
+		ExactAdvance: [2]float64{float64(width), 0},
+		Alpha:        image.NewAlpha(image.Rectangle{}),
+	})
+}
+
+// Remove deletes the glyph for r from the font, reporting whether it
+// existed. CharMap entries for the remaining glyphs are kept valid.
+func (f *Font) Remove(r rune) bool {
+	removed, ok := f.CharMap[r]
+	if !ok {
+		return false
+	}
+
+	index := make(map[*Character]int, len(f.Characters))
+	characters := make([]Character, 0, len(f.Characters)-1)
+	for i := range f.Characters {
+		if &f.Characters[i] == removed {
+			continue
+		}
+		index[&f.Characters[i]] = len(characters)
+		characters = append(characters, f.Characters[i])
+	}
+
+	for code, c := range f.CharMap {
+		if c == removed {
+			delete(f.CharMap, code)
+			continue
+		}
+		f.CharMap[code] = &characters[index[c]]
+	}
+	f.Characters = characters
+	delete(f.advanceOverrides, r)
+	f.InvalidateCache()
+
+	return true
+}
+
+// dilate returns a copy of a grown by n pixels on each side, with every
+// pixel set to the strongest value within n pixels of it.
+func dilate(a *image.Alpha, n int) *image.Alpha {
+	b := a.Rect
+	out := image.NewAlpha(image.Rect(0, 0, b.Dx()+2*n, b.Dy()+2*n))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := a.AlphaAt(x, y).A
+			if v == 0 {
+				continue
+			}
+			ox, oy := x-b.Min.X, y-b.Min.Y
+			for dy := 0; dy <= 2*n; dy++ {
+				row := out.Pix[(oy+dy)*out.Stride:]
+				for dx := 0; dx <= 2*n; dx++ {
+					if row[ox+dx] < v {
+						row[ox+dx] = v
+					}
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+package bdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestSetBaseline(t *testing.T) {
+	f := mustParse(t, testFont)
+	g := f.SetBaseline(8)
+
+	if g.Ascent != 8 || g.Descent != 0 {
+		t.Errorf("Ascent, Descent = %d, %d, want 8, 0", g.Ascent, g.Descent)
+	}
+	// Every glyph moves up by the two pixels the baseline moved down.
+	if lp := g.CharMap['A'].LowerPoint; lp != [2]int{0, 2} {
+		t.Errorf("'A' origin = %v, want [0 2]", lp)
+	}
+	if lp := g.CharMap['g'].LowerPoint; lp != [2]int{0, 0} {
+		t.Errorf("'g' origin = %v, want [0 0]", lp)
+	}
+	if f.Ascent != 6 || f.CharMap['A'].LowerPoint != [2]int{0, 0} {
+		t.Error("SetBaseline modified the original font")
+	}
+}
+
+func TestDownscaleSmooth(t *testing.T) {
+	f := mustParse(t, testFont).DownscaleSmooth(4)
+
+	if f.BPP != 8 || f.PixelSize != 4 || f.Ascent != 3 || f.Descent != 1 {
+		t.Errorf("BPP, PixelSize, Ascent, Descent = %d, %d, %d, %d, want 8, 4, 3, 1", f.BPP, f.PixelSize, f.Ascent, f.Descent)
+	}
+	if a := f.CharMap['A']; a.Alpha.Rect.Dx() != 2 || a.Alpha.Rect.Dy() != 3 {
+		t.Errorf("'A' is %v, want 2x3", a.Alpha.Rect)
+	}
+
+	intermediate := false
+	for _, v := range f.CharMap['A'].Alpha.Pix {
+		if v != 0 && v != 0xff {
+			intermediate = true
+		}
+	}
+	if !intermediate {
+		t.Error("downscaled 'A' has no intermediate alpha values")
+	}
+
+	// Advances follow the glyphs: 5 * 0.5 rounds to 3.
+	if a, _ := f.NewFace().GlyphAdvance('A'); a != fixed.I(3) {
+		t.Errorf("GlyphAdvance('A') = %v, want 3", a)
+	}
+	if w := f.MeasureString("AB"); w != fixed.I(6) {
+		t.Errorf("MeasureString(\"AB\") = %v, want 6", w)
+	}
+}
+
+func TestDownscaleSmoothMetrics(t *testing.T) {
+	src := mustParse(t, editGlyph(testFont, "B", func(block string) string {
+		return strings.Replace(block, "DWIDTH 5 0", "DWIDTH 6.5 0", 1)
+	}))
+	src.OverrideAdvance('g', 8)
+	src.Kerning = map[[2]rune]int{{'A', 'B'}: -2}
+	f := src.DownscaleSmooth(4)
+
+	// 6.5 * 0.5 is 3.25, not the 3.5 that rescaling the rounded 7 gives.
+	if b := f.CharMap['B']; b.Advance[0] != 3 || b.ExactAdvance[0] != 3.25 {
+		t.Errorf("'B' Advance, ExactAdvance = %d, %v, want 3, 3.25", b.Advance[0], b.ExactAdvance[0])
+	}
+	if a, _ := f.NewFace().GlyphAdvance('g'); a != fixed.I(4) {
+		t.Errorf("overridden GlyphAdvance('g') = %v, want 4", a)
+	}
+	if k := f.Kerning[[2]rune{'A', 'B'}]; k != -1 {
+		t.Errorf("Kerning['A', 'B'] = %d, want -1", k)
+	}
+	if src.advanceOverrides['g'] != 8 || src.Kerning[[2]rune{'A', 'B'}] != -2 {
+		t.Error("DownscaleSmooth modified the original font")
+	}
+}
+
+func TestRequantizeBPP(t *testing.T) {
+	f := mustParse(t, grayFont)
+
+	g, err := f.RequantizeBPP(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.BPP != 2 {
+		t.Errorf("BPP = %d, want 2", g.BPP)
+	}
+	// 0x8 is 136 at 4 bits, nearest to the 2-bit level 170.
+	if got := g.CharMap['C'].Alpha.Pix; !bytes.Equal(got, []byte{0xff, 170}) {
+		t.Errorf("'C' pixels = %v, want [255 170]", got)
+	}
+
+	if _, err := f.RequantizeBPP(8); err == nil {
+		t.Error("RequantizeBPP(8) of a 4-bit font succeeded")
+	}
+	if _, err := f.RequantizeBPP(3); err == nil {
+		t.Error("RequantizeBPP(3) succeeded")
+	}
+}
+
+func TestEnsureSpace(t *testing.T) {
+	f := mustParse(t, latin1Font)
+	f.EnsureSpace(3)
+
+	face := f.NewFace()
+	if a, ok := face.GlyphAdvance(' '); !ok || a != fixed.I(3) {
+		t.Errorf("GlyphAdvance(' ') = %v, %v, want 3", a, ok)
+	}
+	if c := f.CharMap['A']; c == nil || c.Name != "A" || c != &f.Characters[0] {
+		t.Error("CharMap['A'] no longer points into Characters")
+	}
+
+	f.EnsureSpace(9)
+	if a, _ := face.GlyphAdvance(' '); a != fixed.I(3) || len(f.Characters) != 3 {
+		t.Errorf("second EnsureSpace replaced the space: advance %v, %d characters", a, len(f.Characters))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	f := mustParse(t, testFont)
+	before := drawString(f.NewFace(), "Ag")
+
+	if !f.Remove('B') {
+		t.Fatal("Remove('B') = false")
+	}
+	if f.HasGlyph('B') || len(f.Characters) != 3 {
+		t.Errorf("HasGlyph('B') = %v with %d characters, want false with 3", f.HasGlyph('B'), len(f.Characters))
+	}
+	for r, c := range f.CharMap {
+		if c.Encoding != r {
+			t.Errorf("CharMap[%q] points at %q", r, c.Name)
+		}
+	}
+	if after := drawString(f.NewFace(), "Ag"); !bytes.Equal(before.Pix, after.Pix) {
+		t.Error("remaining glyphs render differently")
+	}
+
+	if f.Remove('B') {
+		t.Error("second Remove('B') = true")
+	}
+}
+
+func TestToMonospace(t *testing.T) {
+	f := mustParse(t, hxFont)
+
+	for name, g := range map[string]*Font{"centred": f.ToMonospace(7), "left": f.ToMonospaceLeft(7)} {
+		face := g.NewFace()
+		for _, r := range "Hx" {
+			if a, _ := face.GlyphAdvance(r); a != fixed.I(7) {
+				t.Errorf("%s: GlyphAdvance(%q) = %v, want 7", name, r, a)
+			}
+		}
+		if !g.IsMonospace() {
+			t.Errorf("%s: IsMonospace() = false", name)
+		}
+	}
+
+	if x := f.ToMonospace(7).CharMap['H'].LowerPoint[0]; x != 1 {
+		t.Errorf("centred 'H' offset = %d, want 1", x)
+	}
+	if x := f.ToMonospaceLeft(7).CharMap['H'].LowerPoint[0]; x != 0 {
+		t.Errorf("left-aligned 'H' offset = %d, want 0", x)
+	}
+	if f.CharMap['x'].Advance[0] != 4 {
+		t.Error("ToMonospace modified the original font")
+	}
+}
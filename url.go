@@ -0,0 +1,42 @@
+package bdf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxURLBytes limits the size of fonts fetched by ParseURL.
+var MaxURLBytes int64 = 32 << 20
+
+// ParseURL fetches and parses the font at url.
+func ParseURL(ctx context.Context, url string) (*Font, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bdf: fetching %s: %s", url, resp.Status)
+	}
+	if resp.ContentLength > MaxURLBytes {
+		return nil, fmt.Errorf("bdf: %s is %d bytes, more than the %d byte limit", url, resp.ContentLength, MaxURLBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxURLBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxURLBytes {
+		return nil, fmt.Errorf("bdf: %s is more than the %d byte limit", url, MaxURLBytes)
+	}
+
+	return Parse(data)
+}
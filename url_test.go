@@ -0,0 +1,43 @@
+package bdf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/test.bdf" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testFont))
+	}))
+	defer srv.Close()
+
+	f, err := ParseURL(context.Background(), srv.URL+"/test.bdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Characters) != 4 {
+		t.Errorf("got %d characters, want 4", len(f.Characters))
+	}
+
+	if _, err := ParseURL(context.Background(), srv.URL+"/missing.bdf"); err == nil {
+		t.Error("ParseURL of a missing font succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseURL(ctx, srv.URL+"/test.bdf"); err == nil {
+		t.Error("ParseURL with a cancelled context succeeded")
+	}
+
+	defer func(limit int64) { MaxURLBytes = limit }(MaxURLBytes)
+	MaxURLBytes = 100
+	if _, err := ParseURL(context.Background(), srv.URL+"/test.bdf"); err == nil {
+		t.Error("ParseURL of a font over MaxURLBytes succeeded")
+	}
+}
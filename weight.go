@@ -0,0 +1,60 @@
+package bdf
+
+import (
+	"strings"
+)
+
+// relativeWeights maps RELATIVE_WEIGHT values to CSS weights. X11 treats 50
+// (Medium) as the normal weight, which CSS calls 400.
+var relativeWeights = map[int]int{
+	10: 100,
+	20: 200,
+	30: 300,
+	40: 400,
+	50: 400,
+	60: 600,
+	70: 700,
+	80: 800,
+	90: 900,
+}
+
+var weightNames = map[string]int{
+	"thin":       100,
+	"ultralight": 200,
+	"extralight": 200,
+	"light":      300,
+	"book":       400,
+	"normal":     400,
+	"regular":    400,
+	"medium":     500,
+	"demibold":   600,
+	"semibold":   600,
+	"bold":       700,
+	"extrabold":  800,
+	"ultrabold":  800,
+	"black":      900,
+	"heavy":      900,
+}
+
+// WeightClass returns a CSS-style weight from 100 to 900, taken from
+// RELATIVE_WEIGHT, then WEIGHT_NAME, then the XLFD weight field, and
+// defaulting to 400.
+func (f *Font) WeightClass() int {
+	if w, ok := relativeWeights[f.RelativeWeight]; ok {
+		return w
+	}
+
+	name := f.Properties["WEIGHT_NAME"]
+	if name == "" {
+		if x, ok := f.XLFD(); ok {
+			name = x.Weight
+		}
+	}
+	name = strings.ReplaceAll(strings.ToLower(name), " ", "")
+	name = strings.ReplaceAll(name, "-", "")
+	if w, ok := weightNames[name]; ok {
+		return w
+	}
+
+	return 400
+}
@@ -0,0 +1,26 @@
+package bdf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWeightClass(t *testing.T) {
+	bold := strings.Replace(testFont, "-medium-", "-bold-", 1)
+	tests := map[string]struct {
+		src  string
+		want int
+	}{
+		"XLFD medium":     {testFont, 500},
+		"XLFD bold":       {bold, 700},
+		"WEIGHT_NAME":     {strings.Replace(testFont, "STARTPROPERTIES 6", "STARTPROPERTIES 7\nWEIGHT_NAME \"Demi Bold\"", 1), 600},
+		"RELATIVE_WEIGHT": {strings.Replace(bold, "STARTPROPERTIES 6", "STARTPROPERTIES 7\nRELATIVE_WEIGHT 90", 1), 900},
+		"unnamed":         {strings.Replace(testFont, "FONT -test-fixed-medium-r-normal--8-80-75-75-C-50-ISO10646-1", "FONT test", 1), 400},
+	}
+
+	for name, tt := range tests {
+		if got := mustParse(t, tt.src).WeightClass(); got != tt.want {
+			t.Errorf("%s: WeightClass() = %d, want %d", name, got, tt.want)
+		}
+	}
+}
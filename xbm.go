@@ -0,0 +1,45 @@
+package bdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// WriteXBM writes the glyph as an X bitmap, treating any non-zero alpha as a
+// set pixel.
+func (c *Character) WriteXBM(w io.Writer, name string) error {
+	if c.Alpha == nil {
+		return errors.New("bdf: glyph has no bitmap")
+	}
+
+	var data []byte
+	for _, row := range c.Rows() {
+		for _, b := range row {
+			// XBM stores the leftmost pixel in the least significant bit.
+			data = append(data, bits.Reverse8(b))
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#define %s_width %d\n", name, c.Alpha.Rect.Dx())
+	fmt.Fprintf(&buf, "#define %s_height %d\n", name, c.Alpha.Rect.Dy())
+	fmt.Fprintf(&buf, "static unsigned char %s_bits[] = {", name)
+	for i, b := range data {
+		if i%12 == 0 {
+			buf.WriteString("\n   ")
+		} else {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(&buf, "0x%02x", b)
+		if i < len(data)-1 {
+			buf.WriteString(",")
+		}
+	}
+	buf.WriteString(" };\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
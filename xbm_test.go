@@ -0,0 +1,18 @@
+package bdf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteXBM(t *testing.T) {
+	var buf bytes.Buffer
+	if err := mustParse(t, testFont).CharMap['g'].WriteXBM(&buf, "g"); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "xbm.golden", buf.Bytes())
+
+	if err := (&Character{}).WriteXBM(&buf, "empty"); err == nil {
+		t.Error("WriteXBM without a bitmap succeeded")
+	}
+}
@@ -0,0 +1,65 @@
+package bdf
+
+import (
+	"strings"
+)
+
+// XLFD holds the fields of an X Logical Font Description name such as
+// "-misc-fixed-medium-r-normal--13-120-75-75-C-70-ISO8859-1".
+type XLFD struct {
+	Foundry         string
+	Family          string
+	Weight          string
+	Slant           string
+	SetWidth        string
+	AddStyle        string
+	PixelSize       string
+	PointSize       string
+	ResolutionX     string
+	ResolutionY     string
+	Spacing         string
+	AverageWidth    string
+	CharsetRegistry string
+	CharsetEncoding string
+}
+
+// ParseXLFD splits an XLFD font name into its fields. It reports false unless
+// name starts with a dash and has exactly 14 fields.
+func ParseXLFD(name string) (XLFD, bool) {
+	fields := strings.Split(name, "-")
+	if len(fields) != 15 || fields[0] != "" {
+		return XLFD{}, false
+	}
+
+	return XLFD{
+		Foundry:         fields[1],
+		Family:          fields[2],
+		Weight:          fields[3],
+		Slant:           fields[4],
+		SetWidth:        fields[5],
+		AddStyle:        fields[6],
+		PixelSize:       fields[7],
+		PointSize:       fields[8],
+		ResolutionX:     fields[9],
+		ResolutionY:     fields[10],
+		Spacing:         fields[11],
+		AverageWidth:    fields[12],
+		CharsetRegistry: fields[13],
+		CharsetEncoding: fields[14],
+	}, true
+}
+
+// XLFD parses the font's Name as an XLFD font name.
+func (f *Font) XLFD() (XLFD, bool) {
+	return ParseXLFD(f.Name)
+}
+
+// Family returns the family field of the font's XLFD name, or the whole
+// name if it is not an XLFD.
+func (f *Font) Family() string {
+	x, ok := f.XLFD()
+	if !ok {
+		return f.Name
+	}
+	return x.Family
+}
@@ -0,0 +1,42 @@
+package bdf
+
+import (
+	"testing"
+)
+
+func TestParseXLFD(t *testing.T) {
+	x, ok := ParseXLFD("-misc-fixed-bold-r-semicondensed--13-120-75-75-C-70-ISO8859-1")
+	want := XLFD{
+		Foundry:         "misc",
+		Family:          "fixed",
+		Weight:          "bold",
+		Slant:           "r",
+		SetWidth:        "semicondensed",
+		PixelSize:       "13",
+		PointSize:       "120",
+		ResolutionX:     "75",
+		ResolutionY:     "75",
+		Spacing:         "C",
+		AverageWidth:    "70",
+		CharsetRegistry: "ISO8859",
+		CharsetEncoding: "1",
+	}
+	if !ok || x != want {
+		t.Errorf("ParseXLFD = %+v, %v, want %+v", x, ok, want)
+	}
+
+	for _, name := range []string{"Test Sans 8", "-misc-fixed-bold", "misc-fixed-bold-r-semicondensed--13-120-75-75-C-70-ISO8859-1-"} {
+		if _, ok := ParseXLFD(name); ok {
+			t.Errorf("ParseXLFD(%q) succeeded", name)
+		}
+	}
+}
+
+func TestFamily(t *testing.T) {
+	if got := mustParse(t, testFont).Family(); got != "fixed" {
+		t.Errorf("Family() = %q, want %q", got, "fixed")
+	}
+	if got := (&Font{Name: "Test Sans 8"}).Family(); got != "Test Sans 8" {
+		t.Errorf("Family() of a plain name = %q, want the whole name", got)
+	}
+}